@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/nexthink-oss/ghup/internal/remote"
+)
+
+type CommentOutput struct {
+	Repository   string            `json:"repository" yaml:"repository"`
+	Subject      string            `json:"subject" yaml:"subject"`
+	Marker       string            `json:"marker" yaml:"marker"`
+	Comments     []*remote.Comment `json:"comments" yaml:"comments"`
+	Error        error             `json:"-" yaml:"-"`
+	ErrorMessage string            `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+func (o *CommentOutput) GetError() error {
+	return o.Error
+}
+
+func (o *CommentOutput) SetError(err error) {
+	o.Error = err
+	if err != nil {
+		o.ErrorMessage = err.Error()
+	}
+}
+
+func cmdComment() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "comment [flags]",
+		Short: "Create or update a sticky comment on a pull request, issue or commit.",
+		Long: `Create or update a sticky comment on a pull request, issue or commit.
+
+Sticky comments carry a hidden marker, by which the comment previously posted by the same
+actor is found and updated in place, rather than a new comment being posted each time.
+Older comments bearing the same marker are minimized as outdated.`,
+		Args: cobra.NoArgs,
+		RunE: runCommentCmd,
+	}
+
+	flags := cmd.Flags()
+	flags.Int("pr", 0, "pull request `number`")
+	flags.Int("issue", 0, "issue `number`")
+	flags.String("commit", "", "target commit `commitish`")
+	flags.String("marker", "", "marker `key` identifying the comment")
+	flags.String("body", "", "comment body")
+	flags.String("body-file", "", "read comment body from `file`")
+	flags.Bool("recreate", false, "post a new comment, minimizing previous comments as outdated")
+	flags.Bool("minimize", false, "minimize existing comments as outdated, rather than updating them")
+	flags.Bool("delete", false, "delete existing comments")
+
+	flags.SetNormalizeFunc(normalizeFlags)
+	flags.SortFlags = false
+
+	cmd.MarkFlagsMutuallyExclusive("pr", "issue", "commit")
+	cmd.MarkFlagsMutuallyExclusive("body", "body-file")
+	cmd.MarkFlagsMutuallyExclusive("recreate", "minimize", "delete")
+
+	return cmd
+}
+
+func runCommentCmd(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	marker := viper.GetString("marker")
+	if err := remote.ValidateCommentMarker(marker); err != nil {
+		return err
+	}
+
+	subject := &remote.CommentSubject{
+		Number: max(viper.GetInt("pr"), viper.GetInt("issue")),
+	}
+	commitish := viper.GetString("commit")
+	if subject.Number == 0 && commitish == "" {
+		return errors.New("pull request, issue or commit required")
+	}
+
+	remove := viper.GetBool("delete")
+	minimize := viper.GetBool("minimize")
+
+	body := viper.GetString("body")
+	if bodyFile := viper.GetString("body-file"); bodyFile != "" {
+		content, err := os.ReadFile(bodyFile)
+		if err != nil {
+			return fmt.Errorf("reading comment body: %w", err)
+		}
+		body = string(content)
+	}
+	if body == "" && !remove && !minimize {
+		return errors.New("comment body required")
+	}
+
+	repo := remote.Repo{
+		Owner: viper.GetString("owner"),
+		Name:  viper.GetString("repo"),
+	}
+
+	client, err := remote.NewClient(ctx, &repo)
+	if err != nil {
+		return fmt.Errorf("NewClient(%s): %w", repo, err)
+	}
+
+	if commitish != "" {
+		subject.SHA, err = client.ResolveCommitish(commitish)
+		if err != nil {
+			return fmt.Errorf("ResolveCommitish(%s, %s): %w", repo, commitish, err)
+		}
+		if subject.SHA == "" {
+			return fmt.Errorf("commitish %q not found", commitish)
+		}
+	}
+
+	output := &CommentOutput{
+		Repository: repo.String(),
+		Subject:    subject.String(),
+		Marker:     marker,
+	}
+
+	output.Comments, err = client.FindComments(subject, marker)
+	if err != nil {
+		output.SetError(err)
+		return cmdOutput(cmd, output)
+	}
+	log.Debugf("found %d comments on %s with marker %q", len(output.Comments), subject, marker)
+
+	switch {
+	case remove:
+		output.SetError(deleteComments(client, subject, output.Comments))
+	case minimize:
+		output.SetError(minimizeComments(client, output.Comments))
+	default:
+		var comment *remote.Comment
+		comment, err = upsertComment(client, subject, output.Comments, remote.WithCommentMarker(body, marker), viper.GetBool("recreate"))
+		if comment != nil && comment.Action == remote.CommentCreated {
+			output.Comments = append(output.Comments, comment)
+		}
+		output.SetError(err)
+	}
+
+	return cmdOutput(cmd, output)
+}
+
+// upsertComment updates the latest of the existing comments or, if none or recreate, creates a new comment,
+// then minimizes all others
+func upsertComment(client *remote.Client, subject *remote.CommentSubject, comments []*remote.Comment, body string, recreate bool) (*remote.Comment, error) {
+	var comment *remote.Comment
+	if len(comments) > 0 && !recreate {
+		comment = comments[len(comments)-1]
+		comments = comments[:len(comments)-1]
+
+		restored := comment.Minimized
+		if restored {
+			log.Infof("restoring minimized comment %s", comment.Url)
+			if err := client.MinimizeComment(comment, false); err != nil {
+				return comment, err
+			}
+			comment.Action = remote.CommentUpdated
+		}
+
+		if comment.Body == body {
+			log.Infof("comment %s unchanged", comment.Url)
+			if !restored {
+				comment.Action = remote.CommentUnchanged
+			}
+		} else {
+			log.Infof("updating comment %s", comment.Url)
+			if err := client.UpdateComment(subject, comment, body); err != nil {
+				return comment, err
+			}
+		}
+	} else {
+		var err error
+		log.Infof("commenting on %s", subject)
+		if comment, err = client.CreateComment(subject, body); err != nil {
+			return nil, err
+		}
+	}
+
+	return comment, minimizeComments(client, comments)
+}
+
+// minimizeComments minimizes any comments not already minimized
+func minimizeComments(client *remote.Client, comments []*remote.Comment) error {
+	for _, comment := range comments {
+		if comment.Minimized {
+			continue
+		}
+
+		log.Infof("minimizing outdated comment %s", comment.Url)
+		if err := client.MinimizeComment(comment, true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deleteComments deletes all comments
+func deleteComments(client *remote.Client, subject *remote.CommentSubject, comments []*remote.Comment) error {
+	for _, comment := range comments {
+		log.Infof("deleting comment %s", comment.Url)
+		if err := client.DeleteComment(subject, comment); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,165 @@
+//go:build acceptance
+
+package cmd_test
+
+import (
+	"encoding/json"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/spf13/viper"
+
+	"github.com/nexthink-oss/ghup/cmd"
+	"github.com/nexthink-oss/ghup/internal/remote"
+)
+
+type commentTestArgs struct {
+	Commit   string
+	Marker   string
+	Body     string
+	Recreate bool
+	Minimize bool
+	Delete   bool
+}
+
+func (s *commentTestArgs) Slice() []string {
+	args := []string{"comment"}
+
+	if s.Commit != "" {
+		args = append(args, "--commit", s.Commit)
+	}
+
+	if s.Marker != "" {
+		args = append(args, "--marker", s.Marker)
+	}
+
+	if s.Body != "" {
+		args = append(args, "--body", s.Body)
+	}
+
+	if s.Recreate {
+		args = append(args, "--recreate")
+	}
+
+	if s.Minimize {
+		args = append(args, "--minimize")
+	}
+
+	if s.Delete {
+		args = append(args, "--delete")
+	}
+
+	return args
+}
+
+func TestAccCommentCmd(t *testing.T) {
+	setupTestEnvironment(t)
+
+	marker := "test-" + testRandomString(8)
+
+	// steps run in order, each acting on the comments left by the previous
+	tests := []struct {
+		name            string
+		args            commentTestArgs
+		wantError       bool
+		wantStderr      *regexp.Regexp
+		expectedActions []string
+	}{
+		{
+			name:       "missing subject",
+			args:       commentTestArgs{Marker: marker, Body: "missing"},
+			wantError:  true,
+			wantStderr: regexp.MustCompile(`Error: pull request, issue or commit required`),
+		},
+		{
+			name:       "invalid marker",
+			args:       commentTestArgs{Commit: "main", Marker: "a-->b", Body: "invalid"},
+			wantError:  true,
+			wantStderr: regexp.MustCompile(`Error: invalid marker`),
+		},
+		{
+			name:            "create",
+			args:            commentTestArgs{Commit: "main", Marker: marker, Body: "first"},
+			expectedActions: []string{remote.CommentCreated},
+		},
+		{
+			name:            "unchanged",
+			args:            commentTestArgs{Commit: "main", Marker: marker, Body: "first"},
+			expectedActions: []string{remote.CommentUnchanged},
+		},
+		{
+			name:            "update",
+			args:            commentTestArgs{Commit: "main", Marker: marker, Body: "second"},
+			expectedActions: []string{remote.CommentUpdated},
+		},
+		{
+			name:            "recreate",
+			args:            commentTestArgs{Commit: "main", Marker: marker, Body: "third", Recreate: true},
+			expectedActions: []string{remote.CommentMinimized, remote.CommentCreated},
+		},
+		{
+			name:            "minimize",
+			args:            commentTestArgs{Commit: "main", Marker: marker, Minimize: true},
+			expectedActions: []string{"", remote.CommentMinimized},
+		},
+		{
+			name:            "delete",
+			args:            commentTestArgs{Commit: "main", Marker: marker, Delete: true},
+			expectedActions: []string{remote.CommentDeleted, remote.CommentDeleted},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			viper.Reset()
+
+			spec := testCmdSpec{
+				Args: append([]string{"-vvvv"}, test.args.Slice()...),
+			}
+
+			tt.Logf("args: %+v", spec.Args)
+
+			stdoutBuf, stderrBuf, executeErr := testExecuteCmd(tt, spec)
+
+			stdout := stdoutBuf.Bytes()
+			stderr := stderrBuf.Bytes()
+
+			if os.Getenv("TEST_GHUP_LOG_OUTPUT") != "" {
+				tt.Logf("stdout:\n%s", string(stdout))
+				tt.Logf("stderr:\n%s", string(stderr))
+			}
+
+			if (executeErr != nil) != test.wantError {
+				tt.Fatalf("unexpected error: got %v", executeErr)
+			}
+
+			if test.wantStderr != nil && !test.wantStderr.Match(stderr) {
+				tt.Errorf("unexpected stderr: got %q, want %q", string(stderr), test.wantStderr)
+			}
+
+			if test.wantError {
+				return
+			}
+
+			var output cmd.CommentOutput
+			if err := json.Unmarshal(stdout, &output); err != nil {
+				tt.Fatalf("failed to unmarshal output: %v", err)
+			}
+
+			if output.Marker != marker {
+				tt.Errorf("unexpected marker: got %q, want %q", output.Marker, marker)
+			}
+
+			if len(output.Comments) != len(test.expectedActions) {
+				tt.Fatalf("unexpected comments: got %d, want %d", len(output.Comments), len(test.expectedActions))
+			}
+
+			for i, comment := range output.Comments {
+				if comment.Action != test.expectedActions[i] {
+					tt.Errorf("unexpected action for comment %d: got %q, want %q", i, comment.Action, test.expectedActions[i])
+				}
+			}
+		})
+	}
+}
@@ -1,17 +1,23 @@
 package cmd
 
 import (
+	"bytes"
 	"cmp"
+	"context"
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"sync"
 
 	"github.com/apex/log"
-	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/shurcooL/githubv4"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 
 	"github.com/nexthink-oss/ghup/internal/local"
@@ -19,10 +25,25 @@ import (
 	"github.com/nexthink-oss/ghup/internal/util"
 )
 
+// the trailer marking content commits that --amend may replace
+const (
+	amendTrailerKey   = "Committed-Via"
+	amendTrailerValue = "ghup"
+)
+
 type ContentOutput struct {
 	Repository   string              `json:"repository,omitempty" yaml:"repository,omitempty"`
+	Branch       string              `json:"branch,omitempty" yaml:"branch,omitempty"`
 	SHA          string              `json:"sha" yaml:"sha"`
 	Updated      bool                `json:"updated" yaml:"updated"`
+	Amended      string              `json:"amended,omitempty" yaml:"amended,omitempty"`
+	Reset        string              `json:"reset,omitempty" yaml:"reset,omitempty"`
+	Closed       bool                `json:"closed,omitempty" yaml:"closed,omitempty"`
+	Deleted      bool                `json:"deleted,omitempty" yaml:"deleted,omitempty"`
+	Unverified   bool                `json:"unverified,omitempty" yaml:"unverified,omitempty"`
+	Hooks        []local.HookResult  `json:"hooks,omitempty" yaml:"hooks,omitempty"`
+	Files        []local.FileChange  `json:"files,omitempty" yaml:"files,omitempty"`
+	Changes      []local.FileDiff    `json:"changes,omitempty" yaml:"changes,omitempty"`
 	PullRequest  *remote.PullRequest `json:"pullrequest,omitempty" yaml:"pullrequest,omitempty"`
 	Error        error               `json:"-" yaml:"-"`
 	ErrorMessage string              `json:"error,omitempty" yaml:"error,omitempty"`
@@ -39,6 +60,25 @@ func (o *ContentOutput) SetError(err error) {
 	}
 }
 
+// ContentBranchesOutput reports content changes applied to multiple branches
+type ContentBranchesOutput struct {
+	Repository   string           `json:"repository,omitempty" yaml:"repository,omitempty"`
+	Branches     []*ContentOutput `json:"branches" yaml:"branches"`
+	Error        error            `json:"-" yaml:"-"`
+	ErrorMessage string           `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+func (o *ContentBranchesOutput) GetError() error {
+	return o.Error
+}
+
+func (o *ContentBranchesOutput) SetError(err error) {
+	o.Error = err
+	if err != nil {
+		o.ErrorMessage = err.Error()
+	}
+}
+
 func cmdContent() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "content [flags] [<file-spec> ...]",
@@ -46,32 +86,105 @@ func cmdContent() *cobra.Command {
 		Short:   "Manage repository content.",
 		Long:    `Directly manage repository content via the GitHub API, ensuring verified commits from CI systems.`,
 		Args:    cobra.ArbitraryArgs,
-		RunE:    runContentCmd,
+		RunE:    withNotifyHooks(runContentCmd),
+		Annotations: map[string]string{
+			fanOutAnnotation: "true",
+		},
 	}
 
 	flags := cmd.Flags()
 
+	flags.String("manifest", "", "YAML or JSON manifest `file` describing changes (merged with flags)")
 	flags.Bool("tracked", false, "commit changes to tracked files")
 	flags.Bool("staged", false, "commit staged changes")
 	flags.StringSliceP("copy", "c", []string{}, "remote file-spec to copy (`[src-branch<separator>]src-path[<separator>dst-path]`); non-binary files only!")
-	flags.StringSliceP("update", "u", []string{}, "file-spec to update (`local-path[<separator>remote-path]`)")
+	flags.StringSliceP("update", "u", []string{}, "file-spec to update (`local-path[<separator>remote-path]`); local-path '-' reads stdin")
+	flags.StringArray("exec", []string{}, "command whose output to commit (`command<separator>remote-path`)")
+	flags.String("tar", "", "tar `file` of content to commit ('-' for stdin); '.wh.<name>' entries delete <name>")
 	flags.StringSliceP("delete", "d", []string{}, "`remote-path` to delete")
 	flags.StringP("separator", "s", ":", "file-spec `separator`")
+	flags.Bool("template", false, "render update files as Go templates before committing")
+	flags.StringSlice("values", []string{}, "YAML or JSON values `file` for template rendering")
+	flags.StringToString("var", nil, "extra `key=value` template variables")
 	flags.Bool("allow-empty", false, "allow creating commits with no file changes")
+	flags.Bool("amend", false, "replace the target branch head if it was created by ghup with --amend")
+	flags.Bool("reset-to-base", false, "reset an existing target branch to the base branch head before applying changes")
+	flags.Bool("orphan", false, "create a missing target branch from a root commit holding only the given content")
+	flags.Bool("replace-history", false, "replace the target branch with a single root commit holding only the given content")
+	flags.Bool("close-empty", false, "close the pull request and delete the target branch if its content would match the base branch")
+	flags.String("close-comment", "Closing: the base branch already contains these changes.", "pull request `comment` explaining --close-empty")
+	flags.Bool("skip-hooks", false, "skip configured pre-commit hooks")
+	flags.Bool("show-diff", false, "print a unified diff of queued changes (implied by --dry-run)")
 	addCommitMessageFlags(flags)
-	addBranchFlag(flags)
+	addCommitIdentityFlags(flags)
+	flags.StringSliceP("branch", "b", []string{localRepo.Branch}, "target branch `name` or glob (e.g. 'release/*'); may be repeated")
 	flags.Bool("create-branch", true, "create missing target branch")
 	flags.StringP("base-branch", "B", "", `base branch `+"`name`"+` (default: "[remote-default-branch])"`)
 	addPullRequestFlags(flags)
+	flags.StringSlice("pr-branches", []string{}, "target branch `glob`s for which to open pull requests (default: all); '**' matches across slashes")
+	addPullRequestWaitFlags(flags)
 	addDryRunFlag(flags)
 	addForceFlag(flags)
+	addNotifyFlags(flags)
 
 	flags.SetNormalizeFunc(normalizeFlags)
 	flags.SortFlags = false
 
+	cmd.MarkFlagsMutuallyExclusive("amend", "reset-to-base", "orphan", "replace-history")
+	cmd.MarkFlagsMutuallyExclusive("pr-body", "pr-body-file")
+	cmd.MarkFlagsMutuallyExclusive("pr-merge", "pr-enqueue")
+
 	return cmd
 }
 
+// applyManifest applies manifest settings, which take precedence over environment variables and
+// configuration file, but not over flags given explicitly; file specs are merged separately
+func applyManifest(flags *pflag.FlagSet, manifest *local.Manifest) {
+	for key, value := range manifest.Settings() {
+		if !flags.Changed(key) {
+			viper.Set(key, value)
+		}
+	}
+}
+
+// loadPreCommitHooks loads and validates pre-commit hooks from the configuration file
+func loadPreCommitHooks() (hooks []local.PreCommitHook, err error) {
+	if err := viper.UnmarshalKey("hooks.pre-commit", &hooks); err != nil {
+		return nil, fmt.Errorf("loading pre-commit hooks: %w", err)
+	}
+
+	errs := make([]error, 0)
+	for i := range hooks {
+		if err := hooks[i].Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid pre-commit hooks: %w", errors.Join(errs...))
+	}
+
+	return hooks, nil
+}
+
+// contentJob holds the branch-independent state of a content command
+type contentJob struct {
+	cmd       *cobra.Command
+	args      []string
+	client    *remote.Client
+	repo      remote.Repo
+	prRepo    *remote.Repo   // base repository of pull requests, if not the target repository
+	prClient  *remote.Client // client for prRepo
+	manifest  *local.Manifest
+	separator string
+	author    *remote.Identity
+	committer *remote.Identity
+	stdin     func() ([]byte, error)
+	dryRun    bool
+	force     bool
+	amend     bool
+}
+
 func runContentCmd(cmd *cobra.Command, args []string) (err error) {
 	ctx := cmd.Context()
 
@@ -80,76 +193,260 @@ func runContentCmd(cmd *cobra.Command, args []string) (err error) {
 		return fmt.Errorf("invalid separator")
 	}
 
-	repo := remote.Repo{
-		Owner: viper.GetString("owner"),
-		Name:  viper.GetString("repo"),
+	manifest := &local.Manifest{}
+	if manifestPath := viper.GetString("manifest"); manifestPath != "" {
+		manifest, err = local.LoadManifest(manifestPath, separator, remote.GetAutoMergeChoices())
+		if err != nil {
+			return err
+		}
+		applyManifest(cmd.Flags(), manifest)
 	}
-	targetBranch := viper.GetString("branch")
-	dryRun := viper.GetBool("dry-run")
-	force := viper.GetBool("force")
 
-	output := &ContentOutput{
+	author, committer, err := buildIdentities()
+	if err != nil {
+		return err
+	}
+
+	// co-authors are resolved once, as they are common to all repositories
+	client, err := remote.NewClient(ctx, &remote.Repo{})
+	if err != nil {
+		return fmt.Errorf("NewClient(): %w", err)
+	}
+
+	if err := resolveCoAuthors(client); err != nil {
+		return err
+	}
+
+	amend := viper.GetBool("amend")
+	if amend {
+		// mark the commit so that subsequent runs may amend it
+		trailers := viper.GetStringMapString("trailer")
+		trailers[amendTrailerKey] = amendTrailerValue
+		viper.Set("trailer", trailers)
+	}
+
+	job := &contentJob{
+		cmd:       cmd,
+		args:      args,
+		manifest:  manifest,
+		separator: separator,
+		author:    author,
+		committer: committer,
+		// stdin is buffered, as each target branch receives the same content
+		stdin: sync.OnceValues(func() ([]byte, error) {
+			return io.ReadAll(cmd.InOrStdin())
+		}),
+		dryRun: viper.GetBool("dry-run"),
+		force:  viper.GetBool("force"),
+		amend:  amend,
+	}
+
+	if prRepoSpec := viper.GetString("pr-repo"); prRepoSpec != "" {
+		prRepo, err := remote.ParseRepo(prRepoSpec, viper.GetString("owner"))
+		if err != nil {
+			return fmt.Errorf("--pr-repo: %w", err)
+		}
+		job.prRepo = &prRepo
+	}
+
+	for _, pattern := range viper.GetStringSlice("pr-branches") {
+		if err := util.ValidateGlob(pattern); err != nil {
+			return fmt.Errorf("--pr-branches: %w", err)
+		}
+	}
+
+	branchSpecs := slices.DeleteFunc(viper.GetStringSlice("branch"), func(spec string) bool { return spec == "" })
+	for _, spec := range branchSpecs {
+		if err := util.ValidateGlob(spec); err != nil {
+			return fmt.Errorf("--branch: %w", err)
+		}
+	}
+
+	return runForRepos(cmd, func(ctx context.Context, repo remote.Repo) CommandOutput {
+		return job.commitRepo(ctx, repo, branchSpecs)
+	})
+}
+
+// commitRepo applies the job to each branch of a repository matching the branch specs
+func (job *contentJob) commitRepo(ctx context.Context, repo remote.Repo, branchSpecs []string) CommandOutput {
+	output := &ContentBranchesOutput{
 		Repository: repo.String(),
 	}
 
 	client, err := remote.NewClient(ctx, &repo)
 	if err != nil {
-		return fmt.Errorf("NewClient(%s): %w", repo, err)
+		output.SetError(fmt.Errorf("NewClient(%s): %w", repo, err))
+		return output
+	}
+
+	j := *job
+	j.client, j.repo = client, repo
+
+	if j.prRepo != nil && *j.prRepo != repo {
+		if j.prClient, err = remote.NewClient(ctx, j.prRepo); err != nil {
+			output.SetError(fmt.Errorf("NewClient(%s): %w", j.prRepo, err))
+			return output
+		}
+	}
+
+	targetBranches, err := resolveTargetBranches(client, branchSpecs)
+	if err != nil {
+		output.SetError(err)
+		return output
+	}
+
+	// a single named branch retains the single-branch output
+	if len(branchSpecs) == 1 && !util.IsGlob(branchSpecs[0]) {
+		return j.commitBranch(targetBranches[0])
+	}
+
+	output.Branches = make([]*ContentOutput, 0, len(targetBranches))
+
+	errs := make([]error, 0)
+	for _, targetBranch := range targetBranches {
+		log.Infof("updating branch %q", targetBranch)
+		branchOutput := j.commitBranch(targetBranch)
+		branchOutput.Repository = ""
+		if err := branchOutput.GetError(); err != nil {
+			errs = append(errs, fmt.Errorf("branch %q: %w", targetBranch, err))
+		}
+		output.Branches = append(output.Branches, branchOutput)
+	}
+	output.SetError(errors.Join(errs...))
+
+	return output
+}
+
+// resolveTargetBranches expands branch specs into target branch names, preserving order.
+// Globs match existing branches only, and must match at least one; names may be new branches.
+func resolveTargetBranches(client *remote.Client, specs []string) ([]string, error) {
+	targetBranches, err := util.ExpandBranches(specs, client.ListBranches)
+	if err != nil {
+		return nil, err
+	}
+	if len(targetBranches) == 0 {
+		return nil, errors.New("no target branch specified")
+	}
+
+	return targetBranches, nil
+}
+
+// commitBranch computes and commits the change set for a single target branch
+func (j *contentJob) commitBranch(targetBranch string) *ContentOutput {
+	client, repo, manifest, separator, args := j.client, j.repo, j.manifest, j.separator, j.args
+	dryRun, force, amend := j.dryRun, j.force, j.amend
+	author, committer := j.author, j.committer
+
+	output := &ContentOutput{
+		Repository: repo.String(),
+		Branch:     targetBranch,
 	}
 
 	repoInfo, err := client.GetRepositoryInfo(targetBranch)
 	if err != nil {
-		return fmt.Errorf("GetRepositoryInfo(%s, %s): %w", repo, targetBranch, err)
+		output.SetError(fmt.Errorf("GetRepositoryInfo(%s, %s): %w", repo, targetBranch, err))
+		return output
 	}
 
 	if repoInfo.IsEmpty {
-		return fmt.Errorf("cannot push to empty repository")
+		output.SetError(fmt.Errorf("cannot push to empty repository"))
+		return output
 	}
 
 	targetOid := repoInfo.TargetBranch.Commit
 	targetBranchIsNew := targetOid == ""
 
-	baseBranch := cmp.Or(viper.GetString("base-branch"), repoInfo.DefaultBranch.Name)
+	// pull requests are opened from the target repository, by default to itself, or else to --pr-repo
+	prClient, prRepoInfo, prHeadOwner := client, repoInfo, ""
+	if j.prClient != nil {
+		prClient, prHeadOwner = j.prClient, repo.Owner
+		if prRepoInfo, err = prClient.GetRepositoryInfo(""); err != nil {
+			output.SetError(fmt.Errorf("GetRepositoryInfo(%s): %w", j.prRepo, err))
+			return output
+		}
+	}
+
+	// the base branch is that of the pull request repository, so upstream rather than the fork
+	baseBranch := cmp.Or(viper.GetString("base-branch"), prRepoInfo.DefaultBranch.Name)
 	var baseBranchOid githubv4.GitObjectID
-	if baseBranch == repoInfo.DefaultBranch.Name {
-		baseBranchOid = repoInfo.DefaultBranch.Commit
+	if baseBranch == prRepoInfo.DefaultBranch.Name {
+		baseBranchOid = prRepoInfo.DefaultBranch.Commit
 	} else {
-		baseBranchOid, err = client.GetRefOidV4(baseBranch)
+		baseBranchOid, err = prClient.GetRefOidV4(baseBranch)
 		if err != nil {
 			output.SetError(fmt.Errorf("getting oid for %q: %w", baseBranch, err))
-			return cmdOutput(cmd, output)
+			return output
+		}
+	}
+
+	// a fork shares the objects of its upstream repository, so the upstream base head is used as is
+	if j.prClient != nil {
+		if _, err := client.GetCommitInfo(string(baseBranchOid)); err != nil {
+			output.SetError(fmt.Errorf("%q head of %s (%s) is not available in %s, which must be a fork of it: %w", baseBranch, j.prRepo, baseBranchOid, repo, err))
+			return output
 		}
 	}
 
+	// --orphan and --replace-history publish a root commit holding only the given content
+	replaceHistory := viper.GetBool("replace-history") && !targetBranchIsNew
+	rootCommit := replaceHistory || (targetBranchIsNew && (viper.GetBool("orphan") || viper.GetBool("replace-history")))
+
 	if targetBranchIsNew {
 		log.Debug("target branch is new")
 
 		if !viper.GetBool("create-branch") {
 			output.SetError(fmt.Errorf("branch %q does not exist", targetBranch))
-			return cmdOutput(cmd, output)
+			return output
 		}
 
-		targetOid = baseBranchOid
+		if rootCommit {
+			log.Infof("orphan branch %q will be created with its root commit", targetBranch)
+		} else {
+			targetOid = baseBranchOid
 
-		createRefInput := githubv4.CreateRefInput{
-			RepositoryID: repoInfo.NodeID,
-			Name:         githubv4.String(fmt.Sprintf("refs/heads/%s", targetBranch)),
-			Oid:          targetOid,
-		}
+			createRefInput := githubv4.CreateRefInput{
+				RepositoryID: repoInfo.NodeID,
+				Name:         githubv4.String(fmt.Sprintf("refs/heads/%s", targetBranch)),
+				Oid:          targetOid,
+			}
 
-		if !dryRun {
-			log.Infof("creating target branch %q", targetBranch)
-			log.Debugf("CreateRefInput: %+v", createRefInput)
+			if !dryRun {
+				log.Infof("creating target branch %q", targetBranch)
+				log.Debugf("CreateRefInput: %+v", createRefInput)
 
-			if err := client.CreateRefV4(createRefInput); err != nil {
-				output.SetError(fmt.Errorf("creating branch %q: %w", targetBranch, err))
-				return cmdOutput(cmd, output)
+				if err := client.CreateRefV4(createRefInput); err != nil {
+					output.SetError(fmt.Errorf("creating branch %q: %w", targetBranch, err))
+					return output
+				}
+			} else {
+				log.Infof("dry-run: skipping creation of branch: %q from %s", targetBranch, targetOid)
 			}
-		} else {
-			log.Infof("dry-run: skipping creation of branch: %q from %s", targetBranch, targetOid)
 		}
 	}
 
+	// with --amend, changes apply to the parent of an amendable head, carrying over the head's own changes
+	var amendHead *remote.CommitInfo
+	if amend && !targetBranchIsNew {
+		amendHead, err = getAmendableHead(client, string(targetOid), string(baseBranchOid))
+		if err != nil {
+			output.SetError(fmt.Errorf("checking %q for amendment: %w", targetBranch, err))
+			return output
+		}
+	}
+
+	// with --reset-to-base, changes apply to the base branch head, replacing the target branch history
+	parentOid := targetOid
+	resetBranch := viper.GetBool("reset-to-base") && !targetBranchIsNew && targetOid != baseBranchOid
+	if resetBranch {
+		parentOid = baseBranchOid
+	}
+	if amendHead != nil {
+		parentOid = githubv4.GitObjectID(amendHead.Parents[0])
+	}
+	if rootCommit {
+		parentOid = ""
+	}
+
 	pathContent := make(local.PathContent)
 	deletionSet := make(local.DeletionSet)
 
@@ -177,7 +474,48 @@ func runContentCmd(cmd *cobra.Command, args []string) (err error) {
 		}
 	}
 
-	for _, spec := range viper.GetStringSlice("copy") {
+	// stdin may feed a single content source
+	stdinUsed := false
+	readStdin := func() ([]byte, error) {
+		if stdinUsed {
+			return nil, errors.New("stdin may only be used by one content source")
+		}
+		stdinUsed = true
+		return j.stdin()
+	}
+
+	if tarPath := viper.GetString("tar"); tarPath != "" {
+		var tarStream io.Reader
+		if tarPath == local.StdinSource {
+			if stdin, err := readStdin(); err != nil {
+				errs = append(errs, fmt.Errorf("tar %q: %w", tarPath, err))
+			} else {
+				tarStream = bytes.NewReader(stdin)
+			}
+		} else if f, err := os.Open(tarPath); err != nil {
+			errs = append(errs, fmt.Errorf("opening tar %q: %w", tarPath, err))
+		} else {
+			defer func() { _ = f.Close() }()
+			tarStream = f
+		}
+
+		if tarStream != nil {
+			tarContent, tarDeletions, err := local.ReadTar(tarStream)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("tar %q: %w", tarPath, err))
+			}
+			for path, content := range tarContent {
+				pathContent[path] = content
+				delete(deletionSet, path)
+			}
+			for path := range tarDeletions {
+				deletionSet[path] = struct{}{}
+				delete(pathContent, path)
+			}
+		}
+	}
+
+	for spec := range util.SliceChain(manifest.Copies, viper.GetStringSlice("copy")) {
 		branch, source, target, err := local.ParseCopySpec(spec, separator)
 		if err != nil {
 			errs = append(errs, fmt.Errorf("copy spec %q: %w", spec, err))
@@ -189,14 +527,87 @@ func runContentCmd(cmd *cobra.Command, args []string) (err error) {
 		}
 	}
 
-	for spec := range util.SliceChain(viper.GetStringSlice("update"), args) {
+	// moves read from the target branch, unless it is yet to be created in dry-run mode, or is being reset
+	moveBranch := targetBranch
+	if targetBranchIsNew || resetBranch {
+		moveBranch = baseBranch
+	}
+
+	for _, spec := range manifest.Moves {
+		_, source, target, err := local.ParseCopySpec(spec, separator)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("move spec %q: %w", spec, err))
+			continue
+		}
+
+		if client.GetFileHashV4(moveBranch, source) == "" {
+			errs = append(errs, fmt.Errorf("move spec %q: %q does not exist on %q", spec, source, moveBranch))
+			continue
+		}
+
+		content, ok := client.GetFileContentV4(moveBranch, source)
+		if !ok {
+			errs = append(errs, fmt.Errorf("move spec %q: %q is binary; non-binary files only", spec, source))
+			continue
+		}
+
+		pathContent[target] = []byte(content)
+		deletionSet[source] = struct{}{}
+	}
+
+	var templateData *local.TemplateData
+	if viper.GetBool("template") {
+		values, err := local.LoadTemplateValues(viper.GetStringSlice("values"))
+		if err != nil {
+			output.SetError(fmt.Errorf("loading template values: %w", err))
+			return output
+		}
+
+		templateData = &local.TemplateData{
+			Repository: repo.String(),
+			Owner:      repo.Owner,
+			Name:       repo.Name,
+			Branch:     targetBranch,
+			BaseBranch: baseBranch,
+			BaseSHA:    string(baseBranchOid),
+			Values:     values,
+			Vars:       viper.GetStringMapString("var"),
+			Env:        local.TemplateEnv(os.Environ()),
+		}
+	}
+
+	for spec := range util.SliceChain(manifest.Updates, viper.GetStringSlice("update"), args) {
 		source, target, err := local.ParseUpdateSpec(spec, separator)
 		if err != nil {
 			errs = append(errs, fmt.Errorf("update spec %q: %w", spec, err))
 		} else {
-			content, err := os.ReadFile(source)
-			if err != nil {
-				return fmt.Errorf("ReadFile(%s): %w", source, err)
+			var content []byte
+			if source == local.StdinSource {
+				if target == local.StdinSource {
+					errs = append(errs, fmt.Errorf("update spec %q: %w", spec, local.ErrStdinTarget))
+					continue
+				}
+				content, err = readStdin()
+				if err != nil {
+					errs = append(errs, fmt.Errorf("update spec %q: %w", spec, err))
+					continue
+				}
+			} else {
+				content, err = os.ReadFile(source)
+				if err != nil {
+					output.SetError(fmt.Errorf("ReadFile(%s): %w", source, err))
+					return output
+				}
+			}
+
+			if templateData != nil {
+				data := *templateData
+				data.Path = target
+				content, err = local.RenderTemplate(source, content, data)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("template %q: %w", source, err))
+					continue
+				}
 			}
 
 			pathContent[target] = content
@@ -205,7 +616,26 @@ func runContentCmd(cmd *cobra.Command, args []string) (err error) {
 		}
 	}
 
-	for _, target := range viper.GetStringSlice("delete") {
+	for _, spec := range viper.GetStringSlice("exec") {
+		command, target, err := local.ParseExecSpec(spec, separator)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("exec spec %q: %w", spec, err))
+			continue
+		}
+
+		log.Debugf("running %q for %q", command, target)
+		content, err := local.ExecContent(j.cmd.Context(), command)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("exec spec %q: %w", spec, err))
+			continue
+		}
+
+		pathContent[target] = content
+		// an explicit update overrides previous deletions
+		delete(deletionSet, target)
+	}
+
+	for target := range util.SliceChain(manifest.Deletes, viper.GetStringSlice("delete")) {
 		target = filepath.Clean(target)
 		deletionSet[target] = struct{}{}
 		// an explicit deletion overrides previous updates
@@ -214,37 +644,62 @@ func runContentCmd(cmd *cobra.Command, args []string) (err error) {
 
 	if len(errs) > 0 {
 		output.SetError(fmt.Errorf("parsing content specs: %w", errors.Join(errs...)))
-		return cmdOutput(cmd, output)
+		return output
+	}
+
+	if !viper.GetBool("skip-hooks") {
+		hooks, err := loadPreCommitHooks()
+		if err != nil {
+			output.SetError(err)
+			return output
+		}
+
+		output.Hooks, err = pathContent.ApplyHooks(j.cmd.Context(), hooks)
+		if err != nil {
+			output.SetError(fmt.Errorf("running pre-commit hooks: %w", err))
+			return output
+		}
+	}
+
+	if amendHead != nil {
+		if err := carryOverChanges(client, amendHead, pathContent, deletionSet); err != nil {
+			output.SetError(fmt.Errorf("amending %s on %q: %w", amendHead.SHA, targetBranch, err))
+			return output
+		}
 	}
 
 	// we now have the full set of changes, so can proceed to calculate idempotent operations
 
+	getRemoteHash := func(path string) string {
+		if parentOid == "" {
+			return "" // root commit: all content is new
+		}
+		return client.GetFileHashV4(string(parentOid), path)
+	}
+
+	output.Files = local.PlanChanges(pathContent, deletionSet, getRemoteHash, force, rootCommit)
+
 	additionMap := make(map[string]githubv4.FileAddition, 0)
 	deletionMap := make(map[string]githubv4.FileDeletion, 0)
 
-	for path, content := range pathContent {
-		localHash := plumbing.ComputeHash(plumbing.BlobObject, content).String()
-		remoteHash := client.GetFileHashV4(targetBranch, path)
-		if localHash != remoteHash || force {
-			additionMap[path] = githubv4.FileAddition{
-				Path:     githubv4.String(path),
-				Contents: githubv4.Base64String(base64.StdEncoding.EncodeToString(content)),
+	for _, file := range output.Files {
+		switch file.Action {
+		case local.ChangeAdded, local.ChangeModified:
+			additionMap[file.Path] = githubv4.FileAddition{
+				Path:     githubv4.String(file.Path),
+				Contents: githubv4.Base64String(base64.StdEncoding.EncodeToString(pathContent[file.Path])),
+			}
+		case local.ChangeDeleted:
+			deletionMap[file.Path] = githubv4.FileDeletion{
+				Path: githubv4.String(file.Path),
 			}
-			log.Debugf("%q queued for addition", path)
-		} else {
-			log.Debugf("%q (%s) on target branch: skipping addition", path, remoteHash)
 		}
 	}
 
-	for path := range deletionSet {
-		remoteHash := client.GetFileHashV4(targetBranch, path)
-		if remoteHash != "" || force {
-			deletionMap[path] = githubv4.FileDeletion{
-				Path: githubv4.String(path),
-			}
-			log.Debugf("%q queued for deletion", path)
-		} else {
-			log.Debugf("%q absent on target branch: skipping deletion", path)
+	if dryRun || viper.GetBool("show-diff") {
+		output.Changes = diffChanges(client, string(parentOid), pathContent, additionMap, deletionMap)
+		for _, change := range output.Changes {
+			fmt.Fprint(j.cmd.ErrOrStderr(), change.String())
 		}
 	}
 
@@ -254,10 +709,86 @@ func runContentCmd(cmd *cobra.Command, args []string) (err error) {
 	numChanges := len(additions) + len(deletions)
 	allowEmpty := viper.GetBool("allow-empty")
 
-	if numChanges == 0 && !allowEmpty {
+	if rootCommit && len(additions) == 0 {
+		output.SetError(fmt.Errorf("root commit on %q requires content", targetBranch))
+		return output
+	}
+
+	closable := !targetBranchIsNew && !rootCommit && targetBranch != baseBranch && targetBranch != repoInfo.DefaultBranch.Name
+	if viper.GetBool("close-empty") && closable {
+		matches, err := matchesBase(client, string(baseBranchOid), string(parentOid), additions, deletions, force, dryRun)
+		if err != nil {
+			output.SetError(fmt.Errorf("comparing %q with %q: %w", targetBranch, baseBranch, err))
+			return output
+		}
+		if matches {
+			log.Infof("content of %q would match %q: closing", targetBranch, baseBranch)
+			pullRequest := &remote.PullRequest{Head: targetBranch, Base: baseBranch, HeadOwner: prHeadOwner}
+			if err := closeEmptyBranch(client, prClient, output, pullRequest, dryRun); err != nil {
+				output.SetError(err)
+			}
+			output.SHA = string(baseBranchOid)
+			return output
+		}
+	}
+
+	// amending and resetting rebuild the branch on a new parent, unless that would not change its content
+	var resetTree string
+	if (amendHead != nil || resetBranch) && !dryRun {
+		resetTree, err = client.RebuildTree(string(parentOid), string(targetOid), additions, deletions)
+		if err != nil {
+			output.SetError(fmt.Errorf("rebuilding %q on %s: %w", targetBranch, parentOid, err))
+			return output
+		}
+		if resetTree == "" {
+			log.Infof("resulting tree is identical to %q: skipping rebuild", targetBranch)
+			amendHead = nil
+			resetBranch = false
+			numChanges = 0
+			allowEmpty = false
+		}
+	}
+	rebuildBranch := amendHead != nil || resetBranch
+
+	var rootTree string
+	if replaceHistory && !dryRun {
+		rootTree, err = client.RootTree(string(targetOid), additions)
+		if err != nil {
+			output.SetError(fmt.Errorf("replacing history of %q: %w", targetBranch, err))
+			return output
+		}
+		if rootTree == "" {
+			log.Infof("%q is already a root commit with identical content: skipping", targetBranch)
+			rootCommit = false
+			numChanges = 0
+			allowEmpty = false
+		}
+	}
+
+	if amendHead != nil {
+		log.Infof("amending %s on %q", amendHead.SHA, targetBranch)
+		output.Amended = amendHead.SHA
+	}
+	if resetBranch {
+		log.Infof("resetting %q to %q (%s)", targetBranch, baseBranch, baseBranchOid)
+		output.Reset = string(targetOid)
+	}
+
+	if numChanges == 0 && !allowEmpty && !rebuildBranch {
 		log.Info("no changes to commit")
 		output.SHA = string(targetOid)
 		output.Updated = false
+	} else if numChanges == 0 && !allowEmpty {
+		// the requested content matches the new parent, so simply reset to it
+		output.SHA = string(parentOid)
+		output.Updated = true
+
+		if !dryRun {
+			if err := client.ForceUpdateBranch(targetBranch, string(targetOid), string(parentOid)); err != nil {
+				output.SetError(fmt.Errorf("rebuilding %q on %s: %w", targetBranch, parentOid, err))
+				return output
+			}
+		}
 	} else {
 		changes := githubv4.FileChanges{
 			Additions: &additions,
@@ -266,72 +797,123 @@ func runContentCmd(cmd *cobra.Command, args []string) (err error) {
 
 		message := util.BuildCommitMessage()
 
-		input := githubv4.CreateCommitOnBranchInput{
-			Branch:          remote.CommittableBranch(repo, targetBranch),
-			Message:         remote.CommitMessage(message),
-			ExpectedHeadOid: targetOid,
-			FileChanges:     &changes,
-		}
-
 		if numChanges == 0 && allowEmpty {
 			log.Info("creating empty commit")
 		}
 
-		log.Debugf("CreateCommitOnBranchInput: %+v", input)
+		if rootCommit || author != nil || committer != nil {
+			// createCommitOnBranch can neither create root commits nor set explicit identities,
+			// so fall back to the Git Data API
+			log.Warn("root commit or explicit author/committer requested: commit will not be verified by GitHub")
+			output.Unverified = true
 
-		if !dryRun {
-			sha, _, err := client.CreateCommitOnBranchV4(input)
-			if err != nil {
-				output.SetError(fmt.Errorf("committing changes: %w", err))
-				return cmdOutput(cmd, output)
+			commit := remote.GitDataCommit{
+				Branch:    targetBranch,
+				Parents:   []string{string(parentOid)},
+				Message:   message,
+				Additions: additions,
+				Deletions: deletions,
+				Author:    author,
+				Committer: committer,
+			}
+
+			if rebuildBranch {
+				commit.Tree = resetTree
+				commit.Force = true
+				commit.ExpectedHead = string(targetOid)
+			}
+
+			if rootCommit {
+				commit.Parents = nil
+				commit.Tree = rootTree
+				commit.CreateBranch = targetBranchIsNew
+				if replaceHistory {
+					log.Infof("replacing history of %q", targetBranch)
+					commit.Force = true
+					commit.ExpectedHead = string(targetOid)
+					output.Reset = string(targetOid)
+				}
 			}
 
-			output.SHA = string(sha)
+			log.Debugf("GitDataCommit: %+v", commit)
+
+			if !dryRun {
+				sha, err := client.CreateCommitOnBranchV3(commit)
+				if err != nil {
+					output.SetError(fmt.Errorf("committing changes: %w", err))
+					return output
+				}
+
+				output.SHA = sha
+			}
+		} else {
+			input := githubv4.CreateCommitOnBranchInput{
+				Branch:          remote.CommittableBranch(repo, targetBranch),
+				Message:         remote.CommitMessage(message),
+				ExpectedHeadOid: targetOid,
+				FileChanges:     &changes,
+			}
+
+			log.Debugf("CreateCommitOnBranchInput: %+v", input)
+
+			if !dryRun {
+				var sha githubv4.GitObjectID
+				var err error
+				if rebuildBranch {
+					sha, err = client.RebuildBranchV4(targetBranch, string(targetOid), string(parentOid), input.Message, changes)
+				} else {
+					sha, _, err = client.CreateCommitOnBranchV4(input)
+				}
+				if err != nil {
+					output.SetError(fmt.Errorf("committing changes: %w", err))
+					return output
+				}
+
+				output.SHA = string(sha)
+			}
 		}
 
 		output.Updated = true
 	}
 
 	// if we created target branch and there were no changes, tidy up
-	if targetBranchIsNew && numChanges == 0 && !allowEmpty {
+	if targetBranchIsNew && !rootCommit && numChanges == 0 && !allowEmpty {
 		if err := client.DeleteRef(fmt.Sprintf("refs/heads/%s", targetBranch)); err != nil {
 			output.SetError(fmt.Errorf("deleting empty target branch %q: %w", targetBranch, err))
-			return cmdOutput(cmd, output)
+			return output
 		}
-	} else if prTitle := viper.GetString("pr-title"); prTitle != "" && output.SHA != string(baseBranchOid) {
-		// Get auto-merge method, with backward compatibility
-		autoMergeMode := viper.GetString("pr-auto-merge")
+	} else if prTitle := viper.GetString("pr-title"); prTitle != "" && output.SHA != string(baseBranchOid) && wantsPullRequest(targetBranch) {
+		autoMergeMode := prRepoInfo.EffectiveAutoMergeMode(viper.GetString("pr-auto-merge"))
 
-		// Check if auto-merge is requested but not supported by the repository
-		if autoMergeMode != remote.AutoMergeOff && !repoInfo.AutoMergeAllowed {
-			log.Warnf("repository %s does not have auto-merge enabled; ignoring --pr-auto-merge flag", repo)
-			autoMergeMode = remote.AutoMergeOff
-		}
-
-		// Check if the specific merge method is supported
-		if autoMergeMode != remote.AutoMergeOff && !repoInfo.IsAutoMergeMethodSupported(autoMergeMode) {
-			supportedMethods := repoInfo.GetSupportedAutoMergeMethods()
-			log.Warnf("repository %s does not support auto-merge method %q; supported methods: %v; using 'off'", repo, autoMergeMode, supportedMethods)
-			autoMergeMode = remote.AutoMergeOff
+		prBody, err := buildPullRequestBody(prClient, repo, output, targetBranch, baseBranch)
+		if err != nil {
+			output.SetError(err)
+			return output
 		}
 
 		pullRequest := remote.PullRequest{
-			RepoId:        repoInfo.NodeID,
+			RepoId:        prRepoInfo.NodeID,
 			Head:          targetBranch,
 			Base:          baseBranch,
+			HeadOwner:     prHeadOwner,
 			Title:         prTitle,
-			Body:          viper.GetString("pr-body"),
+			Body:          prBody,
 			Draft:         viper.GetBool("pr-draft"),
 			AutoMergeMode: autoMergeMode,
 		}
+		if prHeadOwner != "" {
+			pullRequest.HeadRepoId = repoInfo.NodeID
+		}
+
+		metadata := pullRequestMetadata("pr-")
 
 		var prExists bool
 		// check for existing pull request only if target branch was pre-existing
 		if !targetBranchIsNew {
-			prExists, err = client.FindPullRequestUrl(&pullRequest)
+			prExists, err = prClient.FindPullRequestUrl(&pullRequest)
 			if err != nil {
 				output.SetError(fmt.Errorf("searching open pull requests: %w", err))
-				return cmdOutput(cmd, output)
+				return output
 			}
 		}
 
@@ -343,17 +925,31 @@ func runContentCmd(cmd *cobra.Command, args []string) (err error) {
 
 				// Set all fields to match current flags
 				pullRequest.Title = prTitle
-				pullRequest.Body = viper.GetString("pr-body")
+				if prBody == pullRequest.Body {
+					log.Debugf("pull request #%d body unchanged", pullRequest.Number)
+					pullRequest.Body = "" // preserve existing body
+				} else {
+					pullRequest.Body = prBody
+				}
 				pullRequest.Draft = viper.GetBool("pr-draft")
-				pullRequest.AutoMergeMode = autoMergeMode
+				// auto-merge is otherwise left as found, e.g. having been enabled by hand
+				if j.cmd.Flags().Changed("pr-auto-merge") || viper.IsSet("pr-auto-merge") {
+					pullRequest.AutoMergeMode = autoMergeMode
+				}
 
 				if !dryRun {
-					err = client.UpdatePullRequestV4(&pullRequest)
+					err = prClient.UpdatePullRequestV4(&pullRequest)
 					if err != nil {
 						output.SetError(fmt.Errorf("updating pull request: %w", err))
-						return cmdOutput(cmd, output)
+						return output
 					}
 					log.Infof("updated pull request: %s", pullRequest.Url)
+
+					if err := applyPullRequestMetadata(prClient, &pullRequest, metadata); err != nil {
+						output.PullRequest = &pullRequest
+						output.SetError(err)
+						return output
+					}
 				} else {
 					log.Infof("dry-run: would update PR #%d (title: %q, draft: %v)",
 						pullRequest.Number, prTitle, pullRequest.Draft)
@@ -366,16 +962,275 @@ func runContentCmd(cmd *cobra.Command, args []string) (err error) {
 		} else {
 			if !dryRun {
 				log.Debugf("opening pull request from %q to %q", pullRequest.Head, pullRequest.Base)
-				err = client.CreatePullRequestV4(&pullRequest)
+				err = prClient.CreatePullRequestV4(&pullRequest)
 				if err != nil {
 					output.SetError(fmt.Errorf("opening pull request: %w", err))
-					return cmdOutput(cmd, output)
+					return output
+				}
+
+				if err := applyPullRequestMetadata(prClient, &pullRequest, metadata); err != nil {
+					output.PullRequest = &pullRequest
+					output.SetError(err)
+					return output
 				}
 			}
 
 			output.PullRequest = &pullRequest
 		}
+
+		if method := viper.GetString("pr-update-branch"); method != remote.AutoMergeOff {
+			switch {
+			case viper.GetBool("reset-to-base") || rootCommit:
+				log.Debugf("%q is rebuilt from scratch: skipping branch update", targetBranch)
+			case amend:
+				// a merge commit on top of the content commit would prevent subsequent runs from amending it
+				log.Infof("%q is amended: skipping branch update", targetBranch)
+			case dryRun:
+				log.Infof("dry-run: would update pull request branch (%s)", method)
+			default:
+				pullRequest.HeadSHA = output.SHA
+				if err := prClient.UpdatePullRequestBranch(&pullRequest, method, client); err != nil {
+					output.SetError(err)
+					return output
+				}
+			}
+		}
+
+		if mergeMode := viper.GetString("pr-merge"); mergeMode != remote.AutoMergeOff {
+			method, err := prRepoInfo.SelectMergeMethod(mergeMode)
+			if err != nil {
+				output.SetError(fmt.Errorf("merging pull request: %w", err))
+				return output
+			}
+
+			if dryRun {
+				log.Infof("dry-run: would merge pull request (%s)", method)
+			} else if err := prClient.MergePullRequest(&pullRequest, method, viper.GetDuration("pr-merge-retry"), viper.GetDuration("pr-wait-interval"), viper.GetBool("pr-delete-branch")); err != nil {
+				if pullRequest.AutoMergeMode == remote.AutoMergeOff {
+					output.SetError(err)
+					return output
+				}
+				log.Warnf("%v; leaving pull request to auto-merge", err)
+			}
+		}
+
+		if viper.GetBool("pr-enqueue") {
+			if dryRun {
+				log.Infof("dry-run: would add pull request to the merge queue")
+			} else if err := prClient.EnqueuePullRequest(&pullRequest, viper.GetDuration("pr-merge-retry"), viper.GetDuration("pr-wait-interval"), false); err != nil {
+				output.SetError(err)
+				return output
+			}
+		}
+
+		if wait := viper.GetDuration("pr-wait"); wait > 0 && !dryRun {
+			if err := waitForPullRequest(prClient, &pullRequest, wait, viper.GetDuration("pr-wait-interval")); err != nil {
+				output.SetError(err)
+				return output
+			}
+		}
+	}
+
+	return output
+}
+
+// pullRequestTemplatePaths are the locations of a repository's pull request template, in order of precedence
+var pullRequestTemplatePaths = []string{
+	".github/pull_request_template.md",
+	".github/PULL_REQUEST_TEMPLATE.md",
+	"pull_request_template.md",
+	"PULL_REQUEST_TEMPLATE.md",
+	"docs/pull_request_template.md",
+	"docs/PULL_REQUEST_TEMPLATE.md",
+}
+
+// buildPullRequestBody returns the pull request body given via --pr-body or --pr-body-file.
+// With --pr-template, the body is rendered as a Go template, defaulting to the repository's
+// pull request template on the base branch, or else a summary of the changed files.
+func buildPullRequestBody(client *remote.Client, repo remote.Repo, output *ContentOutput, targetBranch, baseBranch string) (string, error) {
+	body, err := pullRequestBody("pr-")
+	if err != nil {
+		return "", err
+	}
+
+	if !viper.GetBool("pr-template") {
+		return body, nil
+	}
+
+	data := local.PullRequestData{
+		Repository: repo.String(),
+		Owner:      repo.Owner,
+		Name:       repo.Name,
+		Branch:     targetBranch,
+		BaseBranch: baseBranch,
+		SHA:        output.SHA,
+		Title:      viper.GetString("pr-title"),
+		RunURL:     util.CIRunURL(),
+		Files:      output.Files,
+		Trailers:   util.BuildTrailers(),
+		Summary:    local.ChangeSummary(output.Files),
+		Vars:       viper.GetStringMapString("var"),
+		Env:        local.TemplateEnv(os.Environ()),
+	}
+
+	if body == "" {
+		for _, templatePath := range pullRequestTemplatePaths {
+			if content, ok := client.GetFileContentV4(baseBranch, templatePath); ok && content != "" {
+				log.Debugf("using pull request template %q", templatePath)
+				body = content
+				break
+			}
+		}
+	}
+
+	if body == "" {
+		return data.Summary, nil
+	}
+
+	rendered, err := local.RenderTemplate("pull request body", []byte(body), data)
+	if err != nil {
+		return "", fmt.Errorf("pull request body: %w", err)
+	}
+
+	return string(rendered), nil
+}
+
+// wantsPullRequest reports whether target branch matches any --pr-branches glob, if given
+func wantsPullRequest(targetBranch string) bool {
+	if util.MatchBranch(viper.GetStringSlice("pr-branches"), targetBranch) {
+		return true
+	}
+	log.Debugf("%q does not match --pr-branches: skipping pull request", targetBranch)
+	return false
+}
+
+// diffChanges compares queued additions and deletions against their content at ref
+func diffChanges(client *remote.Client, ref string, pathContent local.PathContent, additionMap map[string]githubv4.FileAddition, deletionMap map[string]githubv4.FileDeletion) []local.FileDiff {
+	changes := make([]local.FileDiff, 0, len(additionMap)+len(deletionMap))
+
+	remoteContent := func(path string) (content []byte, binary bool) {
+		if ref == "" || client.GetFileHashV4(ref, path) == "" {
+			return nil, false
+		}
+		text, ok := client.GetFileContentV4(ref, path)
+		if !ok {
+			return nil, true
+		}
+		return []byte(text), false
+	}
+
+	for _, path := range slices.Sorted(maps.Keys(additionMap)) {
+		old, oldBinary := remoteContent(path)
+		changes = append(changes, local.DiffContent(path, old, pathContent[path], oldBinary))
+	}
+
+	for _, path := range slices.Sorted(maps.Keys(deletionMap)) {
+		old, oldBinary := remoteContent(path)
+		changes = append(changes, local.DiffContent(path, old, nil, oldBinary))
+	}
+
+	return changes
+}
+
+// getAmendableHead returns the head commit if it may be amended, i.e. it was created by ghup with
+// --amend, has a single parent and is not part of the base branch; otherwise it returns nil.
+func getAmendableHead(client *remote.Client, head, base string) (*remote.CommitInfo, error) {
+	commit, err := client.GetCommitInfo(head)
+	if err != nil {
+		return nil, err
+	}
+
+	if !util.HasTrailer(commit.Message, amendTrailerKey, amendTrailerValue) {
+		log.Warnf("head %s was not created by ghup: refusing to amend", head)
+		return nil, nil
+	}
+
+	if len(commit.Parents) != 1 {
+		log.Warnf("head %s has %d parents: refusing to amend", head, len(commit.Parents))
+		return nil, nil
+	}
+
+	merged, err := client.IsAncestor(head, base)
+	if err != nil {
+		return nil, err
+	}
+	if merged {
+		log.Warnf("head %s is part of the base branch: refusing to amend", head)
+		return nil, nil
+	}
+
+	return commit, nil
+}
+
+// carryOverChanges adds the changes of an amended head relative to its parent to pathContent and
+// deletionSet, for paths not otherwise changed, so that its replacement retains them
+func carryOverChanges(client *remote.Client, head *remote.CommitInfo, pathContent local.PathContent, deletionSet local.DeletionSet) error {
+	parent, err := client.GetCommitInfo(head.Parents[0])
+	if err != nil {
+		return err
+	}
+
+	contents, deletions, err := client.TreeChanges(parent.Tree, head.Tree)
+	if err != nil {
+		return err
+	}
+
+	for path, content := range contents {
+		_, updated := pathContent[path]
+		if _, deleted := deletionSet[path]; !updated && !deleted {
+			pathContent[path] = content
+		}
+	}
+
+	for _, path := range deletions {
+		if _, updated := pathContent[path]; !updated {
+			deletionSet[path] = struct{}{}
+		}
+	}
+
+	return nil
+}
+
+// matchesBase reports whether base already holds every change of the branch resulting from applying
+// changes to parent. Determining this may require creating a tree, so changes on top of another parent are assumed
+// not to match in dry-run mode.
+func matchesBase(client *remote.Client, base, parent string, additions []githubv4.FileAddition, deletions []githubv4.FileDeletion, force, dryRun bool) (bool, error) {
+	if dryRun && len(additions)+len(deletions) > 0 && (parent != base || force) {
+		log.Debug("dry-run: unable to compare resulting tree with base")
+		return false, nil
+	}
+
+	return client.MatchesBase(base, parent, additions, deletions, force)
+}
+
+// closeEmptyBranch closes any open pull request matching pullRequest, via prClient, then deletes its head branch
+func closeEmptyBranch(client, prClient *remote.Client, output *ContentOutput, pullRequest *remote.PullRequest, dryRun bool) error {
+	branch := pullRequest.Head
+
+	found, err := prClient.FindPullRequestUrl(pullRequest)
+	if err != nil {
+		return fmt.Errorf("searching open pull requests: %w", err)
+	}
+
+	if found {
+		output.PullRequest = pullRequest
+		if dryRun {
+			log.Infof("dry-run: would close pull request #%d", pullRequest.Number)
+		} else {
+			log.Infof("closing pull request #%d", pullRequest.Number)
+			if err := prClient.ClosePullRequestV4(pullRequest, viper.GetString("close-comment")); err != nil {
+				return err
+			}
+		}
+		output.Closed = true
+	}
+
+	if dryRun {
+		log.Infof("dry-run: would delete branch %q", branch)
+	} else if err := client.DeleteRef(fmt.Sprintf("refs/heads/%s", branch)); err != nil {
+		return fmt.Errorf("deleting branch %q: %w", branch, err)
 	}
+	output.Deleted = true
 
-	return cmdOutput(cmd, output)
+	return nil
 }
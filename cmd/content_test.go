@@ -4,11 +4,14 @@ package cmd_test
 
 import (
 	"encoding/json"
+	"maps"
 	"os"
 	"path/filepath"
+	"strconv"
 	"testing"
 
 	"github.com/nexthink-oss/ghup/cmd"
+	"github.com/nexthink-oss/ghup/internal/remote"
 )
 
 type contentTestArgs struct {
@@ -162,6 +165,7 @@ func TestAccContentCmd(t *testing.T) {
 		checkJson     bool
 		expectUpdated bool
 		expectPR      bool
+		expectFiles   map[string]string // path to action, if checked
 	}{
 		{
 			name: "Create a new branch with content updates",
@@ -188,6 +192,10 @@ func TestAccContentCmd(t *testing.T) {
 			},
 			checkJson:     true,
 			expectUpdated: false, // Files have not changed, so expect no update
+			expectFiles: map[string]string{
+				"test-path/file1.txt": "unchanged",
+				"test-path/file2.txt": "unchanged",
+			},
 		},
 		{
 			name: "Update a file with new content",
@@ -200,6 +208,9 @@ func TestAccContentCmd(t *testing.T) {
 			},
 			checkJson:     true,
 			expectUpdated: true,
+			expectFiles: map[string]string{
+				"test-path/file2.txt": "modified",
+			},
 		},
 		{
 			name: "Delete a file",
@@ -212,6 +223,9 @@ func TestAccContentCmd(t *testing.T) {
 			},
 			checkJson:     true,
 			expectUpdated: true,
+			expectFiles: map[string]string{
+				"test-path/file2.txt": "deleted",
+			},
 		},
 		{
 			name: "Copy a file from the same branch",
@@ -254,6 +268,11 @@ func TestAccContentCmd(t *testing.T) {
 			},
 			checkJson:     true,
 			expectUpdated: true,
+			expectFiles: map[string]string{
+				"test-path/new-file.txt":     "added",
+				"test-path/another-copy.txt": "added",
+				"test-path/file3.txt":        "skipped", // never existed
+			},
 		},
 		{
 			name: "Create a PR from our changes",
@@ -495,8 +514,388 @@ func TestAccContentCmd(t *testing.T) {
 					if test.expectPR && output.PullRequest == nil {
 						tt.Errorf("expected pull request info in output, but got none")
 					}
+
+					if test.expectFiles != nil {
+						actions := make(map[string]string, len(output.Files))
+						for _, file := range output.Files {
+							actions[file.Path] = file.Action
+						}
+						if !maps.Equal(actions, test.expectFiles) {
+							tt.Errorf("expected files %v, got %v", test.expectFiles, actions)
+						}
+					}
 				}
 			}
 		})
 	}
 }
+
+func TestAccContentCmdMultiBranch(t *testing.T) {
+	_, resources := setupTestResources(t)
+
+	tmpDir := t.TempDir()
+	file := filepath.Join(tmpDir, "ci.yaml")
+	if err := os.WriteFile(file, []byte("on: push\n"), 0o600); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	prefix := "test-multi-" + testRandomString(8)
+	branches := []string{prefix + "/one", prefix + "/two"}
+	for _, branch := range branches {
+		resources.AddBranch(branch)
+	}
+
+	// create both branches, then update them via a glob
+	for _, args := range [][]string{
+		{"content", "-vvvv", "--branch", branches[0], "--branch", branches[1], "--update", file + ":test-path/ci.yaml"},
+		{"content", "-vvvv", "--branch", prefix + "/*", "--update", file + ":test-path/ci.yaml", "--force"},
+	} {
+		stdout, stderr, err := testExecuteCmd(t, testCmdSpec{Args: args})
+		if os.Getenv("TEST_GHUP_LOG_OUTPUT") != "" {
+			t.Logf("stdout:\n%s", stdout.String())
+			t.Logf("stderr:\n%s", stderr.String())
+		}
+		if err != nil {
+			t.Fatalf("content %v: unexpected error: %v", args, err)
+		}
+
+		var output cmd.ContentBranchesOutput
+		if err := json.Unmarshal(stdout.Bytes(), &output); err != nil {
+			t.Fatalf("failed to unmarshal JSON output: %v", err)
+		}
+
+		if len(output.Branches) != len(branches) {
+			t.Fatalf("expected %d branches in output, got %d", len(branches), len(output.Branches))
+		}
+		for i, branch := range output.Branches {
+			if branch.Branch != branches[i] || !branch.Updated {
+				t.Errorf("expected %q to be updated, got %q (updated=%v)", branches[i], branch.Branch, branch.Updated)
+			}
+		}
+	}
+}
+
+func TestAccContentCmdManifest(t *testing.T) {
+	_, resources := setupTestResources(t)
+
+	branch := "test-manifest-" + testRandomString(8)
+	resources.AddBranch(branch)
+
+	// local paths are relative to the manifest, wherever ghup runs from
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "data.txt"), []byte("manifest"), 0o600); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	manifest := filepath.Join(tmpDir, "changes.yaml")
+	content := "branch: " + branch + "\nupdates:\n  - data.txt:test-path/manifest.txt\n"
+	if err := os.WriteFile(manifest, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write manifest: %v", err)
+	}
+
+	// the manifest branch takes precedence over the CI branch environment
+	stdout, stderr, err := testExecuteCmd(t, testCmdSpec{
+		Env:  map[string]string{"BRANCH_NAME": "test-manifest-ignored"},
+		Args: []string{"content", "-vvvv", "--manifest", manifest},
+	})
+	if os.Getenv("TEST_GHUP_LOG_OUTPUT") != "" {
+		t.Logf("stdout:\n%s", stdout.String())
+		t.Logf("stderr:\n%s", stderr.String())
+	}
+	if err != nil {
+		t.Fatalf("content --manifest: unexpected error: %v", err)
+	}
+
+	var output cmd.ContentOutput
+	if err := json.Unmarshal(stdout.Bytes(), &output); err != nil {
+		t.Fatalf("failed to unmarshal JSON output: %v", err)
+	}
+	if output.Branch != branch || !output.Updated {
+		t.Errorf("expected %q to be updated, got %q (updated=%v)", branch, output.Branch, output.Updated)
+	}
+}
+
+// contentTestStep is a content command whose output is checked
+type contentTestStep struct {
+	args  []string
+	check func(t *testing.T, output cmd.ContentOutput)
+}
+
+// testContentSteps runs content commands in sequence, checking the output of each
+func testContentSteps(t *testing.T, steps []contentTestStep) {
+	t.Helper()
+
+	for _, step := range steps {
+		stdout, stderr, err := testExecuteCmd(t, testCmdSpec{Args: append([]string{"content", "-vvvv"}, step.args...)})
+		if os.Getenv("TEST_GHUP_LOG_OUTPUT") != "" {
+			t.Logf("stdout:\n%s", stdout.String())
+			t.Logf("stderr:\n%s", stderr.String())
+		}
+		if err != nil {
+			t.Fatalf("content %v: unexpected error: %v", step.args, err)
+		}
+
+		var output cmd.ContentOutput
+		if err := json.Unmarshal(stdout.Bytes(), &output); err != nil {
+			t.Fatalf("content %v: failed to unmarshal JSON output: %v", step.args, err)
+		}
+		step.check(t, output)
+	}
+}
+
+func TestAccContentCmdAmend(t *testing.T) {
+	_, resources := setupTestResources(t)
+
+	tmpDir := t.TempDir()
+	first := filepath.Join(tmpDir, "first.txt")
+	second := filepath.Join(tmpDir, "second.txt")
+	for path, content := range map[string]string{first: "first", second: "second"} {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write test file: %v", err)
+		}
+	}
+
+	branch := "test-amend-" + testRandomString(8)
+	resources.AddBranch(branch)
+
+	var amendable string
+	testContentSteps(t, []contentTestStep{
+		{
+			args: []string{"--branch", branch, "--amend", "--update", first + ":test-path/first.txt"},
+			check: func(t *testing.T, output cmd.ContentOutput) {
+				if !output.Updated || output.Amended != "" {
+					t.Errorf("expected a new commit, got updated=%v amended=%q", output.Updated, output.Amended)
+				}
+				amendable = output.SHA
+			},
+		},
+		{
+			args: []string{"--branch", branch, "--amend", "--update", second + ":test-path/second.txt"},
+			check: func(t *testing.T, output cmd.ContentOutput) {
+				if output.Amended != amendable {
+					t.Errorf("expected %s to be amended, got %q", amendable, output.Amended)
+				}
+				if output.Unverified {
+					t.Errorf("expected amended commit to be verified")
+				}
+				for _, file := range output.Files {
+					if file.Path == "test-path/first.txt" && file.Action != "added" {
+						t.Errorf("expected changes of amended commit to be carried over, got %s", file.Action)
+					}
+				}
+			},
+		},
+		{
+			args: []string{"--branch", branch, "--amend", "--update", second + ":test-path/second.txt"},
+			check: func(t *testing.T, output cmd.ContentOutput) {
+				if output.Updated || output.Amended != "" {
+					t.Errorf("expected identical content to be skipped, got updated=%v amended=%q", output.Updated, output.Amended)
+				}
+			},
+		},
+	})
+}
+
+func TestAccContentCmdResetToBase(t *testing.T) {
+	client, resources := setupTestResources(t)
+
+	tmpDir := t.TempDir()
+	first := filepath.Join(tmpDir, "first.txt")
+	second := filepath.Join(tmpDir, "second.txt")
+	for path, content := range map[string]string{first: "first", second: "second"} {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write test file: %v", err)
+		}
+	}
+
+	repoInfo, err := client.GetRepositoryInfo("")
+	if err != nil {
+		t.Fatalf("failed to get repository info: %v", err)
+	}
+	baseOid := string(repoInfo.DefaultBranch.Commit)
+
+	branch := "test-reset-" + testRandomString(8)
+	resources.AddBranch(branch)
+
+	var head string
+	testContentSteps(t, []contentTestStep{
+		{
+			args: []string{"--branch", branch, "--update", first + ":test-path/first.txt"},
+			check: func(t *testing.T, output cmd.ContentOutput) {
+				head = output.SHA
+			},
+		},
+		{
+			args: []string{"--branch", branch, "--reset-to-base", "--update", second + ":test-path/second.txt"},
+			check: func(t *testing.T, output cmd.ContentOutput) {
+				if output.Reset != head {
+					t.Errorf("expected %s to be reset, got %q", head, output.Reset)
+				}
+				if output.Unverified {
+					t.Errorf("expected reset commit to be verified")
+				}
+				if len(output.Files) != 1 || output.Files[0].Path != "test-path/second.txt" {
+					t.Errorf("expected only test-path/second.txt to be committed, got %v", output.Files)
+				}
+				head = output.SHA
+			},
+		},
+		{
+			args: []string{"--branch", branch, "--reset-to-base", "--update", second + ":test-path/second.txt"},
+			check: func(t *testing.T, output cmd.ContentOutput) {
+				if output.Updated || output.SHA != head {
+					t.Errorf("expected identical content to be skipped, got updated=%v sha=%s", output.Updated, output.SHA)
+				}
+			},
+		},
+		{
+			args: []string{"--branch", branch, "--reset-to-base", "--delete", "test-path/second.txt"},
+			check: func(t *testing.T, output cmd.ContentOutput) {
+				if output.Reset != head || output.SHA != baseOid {
+					t.Errorf("expected %s to be reset to %s, got reset=%q sha=%s", head, baseOid, output.Reset, output.SHA)
+				}
+			},
+		},
+	})
+}
+
+func TestAccContentCmdCloseEmpty(t *testing.T) {
+	_, resources := setupTestResources(t)
+
+	tmpDir := t.TempDir()
+	file := filepath.Join(tmpDir, "obsolete.txt")
+	if err := os.WriteFile(file, []byte("obsolete"), 0o600); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	branch := "test-close-empty-" + testRandomString(8)
+	resources.AddBranch(branch)
+
+	testContentSteps(t, []contentTestStep{
+		{
+			args: []string{"--branch", branch, "--close-empty", "--update", file + ":test-path/obsolete.txt", "--pr-title", "Test close-empty"},
+			check: func(t *testing.T, output cmd.ContentOutput) {
+				if !output.Updated || output.PullRequest == nil {
+					t.Errorf("expected a commit and pull request, got updated=%v pullrequest=%v", output.Updated, output.PullRequest)
+				}
+				if output.Closed || output.Deleted {
+					t.Errorf("expected branch with changes to be kept, got closed=%v deleted=%v", output.Closed, output.Deleted)
+				}
+			},
+		},
+		{
+			args: []string{"--branch", branch, "--close-empty", "--dry-run", "--delete", "test-path/obsolete.txt", "--pr-title", "Test close-empty"},
+			check: func(t *testing.T, output cmd.ContentOutput) {
+				if output.Closed || output.Deleted {
+					t.Errorf("expected dry-run not to assume a match, got closed=%v deleted=%v", output.Closed, output.Deleted)
+				}
+			},
+		},
+		{
+			args: []string{"--branch", branch, "--close-empty", "--delete", "test-path/obsolete.txt", "--pr-title", "Test close-empty"},
+			check: func(t *testing.T, output cmd.ContentOutput) {
+				if !output.Closed || !output.Deleted {
+					t.Errorf("expected pull request to be closed and branch deleted, got closed=%v deleted=%v", output.Closed, output.Deleted)
+				}
+				if output.Updated {
+					t.Errorf("expected no commit")
+				}
+			},
+		},
+	})
+}
+
+func TestAccContentCmdRootCommits(t *testing.T) {
+	_, resources := setupTestResources(t)
+
+	tmpDir := t.TempDir()
+	page := filepath.Join(tmpDir, "index.html")
+	if err := os.WriteFile(page, []byte("<html></html>"), 0o600); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	orphanBranch := "test-orphan-" + testRandomString(8)
+	replacedBranch := "test-replace-history-" + testRandomString(8)
+	resources.AddBranch(orphanBranch)
+	resources.AddBranch(replacedBranch)
+
+	var head string
+	testContentSteps(t, []contentTestStep{
+		{
+			args: []string{"--branch", orphanBranch, "--orphan", "--update", page + ":index.html"},
+			check: func(t *testing.T, output cmd.ContentOutput) {
+				if !output.Updated || !output.Unverified {
+					t.Errorf("expected an unverified root commit, got updated=%v unverified=%v", output.Updated, output.Unverified)
+				}
+			},
+		},
+		{
+			args: []string{"--branch", replacedBranch, "--update", page + ":test-path/index.html"},
+			check: func(t *testing.T, output cmd.ContentOutput) {
+				head = output.SHA
+			},
+		},
+		{
+			args: []string{"--branch", replacedBranch, "--replace-history", "--update", page + ":index.html"},
+			check: func(t *testing.T, output cmd.ContentOutput) {
+				if output.Reset != head {
+					t.Errorf("expected %s to be replaced, got %q", head, output.Reset)
+				}
+				head = output.SHA
+			},
+		},
+		{
+			args: []string{"--branch", replacedBranch, "--replace-history", "--update", page + ":index.html"},
+			check: func(t *testing.T, output cmd.ContentOutput) {
+				if output.Updated || output.SHA != head {
+					t.Errorf("expected identical root commit to be kept, got updated=%v sha=%s", output.Updated, output.SHA)
+				}
+			},
+		},
+	})
+}
+
+func TestAccContentCmdForkPullRequest(t *testing.T) {
+	_, resources := setupTestResources(t)
+
+	// the test repository must be a fork of TEST_GHUP_PR_REPO
+	upstream := os.Getenv("TEST_GHUP_PR_REPO")
+	if upstream == "" {
+		t.Skip("TEST_GHUP_PR_REPO is not set")
+	}
+	upstreamRepo, err := remote.ParseRepo(upstream, os.Getenv("TEST_GHUP_OWNER"))
+	if err != nil {
+		t.Fatalf("TEST_GHUP_PR_REPO: %v", err)
+	}
+
+	file := filepath.Join(t.TempDir(), "fork.txt")
+	if err := os.WriteFile(file, []byte("from a fork"), 0o600); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	branch := "test-fork-pr-" + testRandomString(8)
+	resources.AddBranch(branch)
+
+	var number int
+	testContentSteps(t, []contentTestStep{
+		{
+			args: []string{"--branch", branch, "--update", file + ":test-path/fork.txt", "--pr-repo", upstream, "--pr-title", "Test fork pull request"},
+			check: func(t *testing.T, output cmd.ContentOutput) {
+				if output.PullRequest == nil || output.PullRequest.HeadOwner != os.Getenv("TEST_GHUP_OWNER") {
+					t.Fatalf("expected a pull request from the fork, got %+v", output.PullRequest)
+				}
+				number = output.PullRequest.Number
+			},
+		},
+		{
+			args: []string{"--branch", branch, "--update", file + ":test-path/fork.txt", "--force", "--pr-repo", upstream, "--pr-title", "Test fork pull request"},
+			check: func(t *testing.T, output cmd.ContentOutput) {
+				if output.PullRequest == nil || output.PullRequest.Number != number {
+					t.Errorf("expected pull request #%d to be found, got %+v", number, output.PullRequest)
+				}
+			},
+		},
+	})
+
+	var closed cmd.PullRequestOutput
+	testPullRequestCmd(t, &closed, "close", strconv.Itoa(number), "--owner", upstreamRepo.Owner, "--repo", upstreamRepo.Name)
+}
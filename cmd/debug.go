@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/shurcooL/githubv4"
@@ -41,6 +42,7 @@ func cmdDebug() *cobra.Command {
 
 	addBranchFlag(flags)
 	addCommitMessageFlags(flags)
+	flags.Bool("resolve-logins", false, "resolve user logins to co-author trailers (requires network access and a token)")
 
 	flags.SetNormalizeFunc(normalizeFlags)
 
@@ -48,24 +50,44 @@ func cmdDebug() *cobra.Command {
 }
 
 func runDebugCmd(cmd *cobra.Command, args []string) error {
+	repo := remote.Repo{
+		Owner: viper.GetString("owner"),
+		Name:  viper.GetString("repo"),
+	}
+
+	errs := make([]error, 0)
+
+	// debug works offline, unless asked to resolve user logins
+	var client *remote.Client
+	if viper.GetBool("resolve-logins") {
+		var err error
+		if client, err = remote.NewClient(cmd.Context(), &repo); err != nil {
+			errs = append(errs, fmt.Errorf("NewClient(%s): %w", repo, err))
+		}
+	}
+	if err := resolveCoAuthors(client); err != nil {
+		errs = append(errs, err)
+	}
+
 	output := &DebugOutput{
 		HasToken: len(viper.GetString("token")) > 0,
 		Trailers: util.BuildTrailers(),
-		Remote: remote.Repo{
-			Owner: viper.GetString("owner"),
-			Name:  viper.GetString("repo"),
-		},
-		Branch:  viper.GetString("branch"),
-		Commit:  localRepo.HeadCommit(),
-		Message: remote.CommitMessage(util.BuildCommitMessage()),
+		Remote:   repo,
+		Branch:   viper.GetString("branch"),
+		Commit:   localRepo.HeadCommit(),
+		Message:  remote.CommitMessage(util.BuildCommitMessage()),
 	}
 
 	status, err := localRepo.Status()
 	if err != nil {
-		output.SetError(fmt.Errorf("local repository status: %w", err))
+		errs = append(errs, fmt.Errorf("local repository status: %w", err))
 	} else {
 		output.Clean = status.IsClean()
 	}
 
+	if len(errs) > 0 {
+		output.Error = errors.Join(errs...).Error()
+	}
+
 	return cmdOutput(cmd, output)
 }
@@ -41,7 +41,7 @@ func cmdDeployment() *cobra.Command {
 		Use:   "deployment [flags] [<environment>]",
 		Short: "Update deployment status for a specific environment.",
 		Args:  cobra.MaximumNArgs(1),
-		RunE:  runDeploymentCmd,
+		RunE:  withNotifyHooks(runDeploymentCmd),
 	}
 
 	flags := cmd.Flags()
@@ -60,6 +60,7 @@ func cmdDeployment() *cobra.Command {
 	flags.String("environment-url", "", "environment URL")
 
 	addDryRunFlag(flags)
+	addNotifyFlags(flags)
 
 	flags.SetNormalizeFunc(normalizeFlags)
 	flags.SortFlags = false
@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/apex/log"
 	"github.com/apex/log/handlers/cli"
@@ -117,15 +119,22 @@ func commonSetup(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	if viper.GetString("owner") == "" {
-		errs = append(errs, fmt.Errorf("owner is required"))
-	}
+	if hasRepoSelectors() {
+		if !supportsRepoSelectors(cmd) {
+			errs = append(errs, fmt.Errorf("%s does not support repository selectors", cmd.Name()))
+		}
+	} else {
+		if viper.GetString("owner") == "" {
+			errs = append(errs, fmt.Errorf("owner is required"))
+		}
 
-	if viper.GetString("repo") == "" {
-		errs = append(errs, fmt.Errorf("repo is required"))
+		if viper.GetString("repo") == "" {
+			errs = append(errs, fmt.Errorf("repo is required"))
+		}
 	}
 
-	if flags.Lookup("branch") != nil && viper.GetString("branch") == "" {
+	// branch may be a single name or, for content, a list of names
+	if flags.Lookup("branch") != nil && !slices.ContainsFunc(viper.GetStringSlice("branch"), func(b string) bool { return b != "" }) {
 		errs = append(errs, fmt.Errorf("branch is required"))
 	}
 
@@ -163,18 +172,143 @@ func addCommitMessageFlags(flagSet *pflag.FlagSet) {
 	flagSet.String("user-trailer", "Co-Authored-By", "`key` for commit author trailer (blank to disable)")
 	flagSet.String("user-name", localRepo.User.Name, "`name` for commit author trailer")
 	flagSet.String("user-email", localRepo.User.Email, "`email` for commit author trailer")
+	flagSet.StringSlice("user-login", nil, "GitHub `login` to resolve to a noreply co-author trailer (overrides user-name/user-email)")
+	flagSet.StringSlice("co-author", nil, "additional co-author `identity` (\"Name <email>\") for commit author trailer")
 	flagSet.StringToString("trailer", nil, "extra `key=value` commit trailers")
 }
 
+// userLogins returns the configured user logins; in GitHub Actions, the triggering user is used
+// only when no login and no user-name/user-email trailer identity is configured
+func userLogins() []string {
+	if viper.IsSet("user-login") || viper.GetString("user-name") != "" || viper.GetString("user-email") != "" {
+		return viper.GetStringSlice("user-login")
+	}
+	if actor := util.GithubActionsActor(); actor != "" {
+		log.Debugf("defaulting user login to GitHub Actions actor %q", actor)
+		return []string{actor}
+	}
+	return nil
+}
+
+// resolveCoAuthors resolves user logins to noreply identities and merges them
+// with explicit co-authors, for use by util.BuildTrailers; without a client,
+// user logins are left unresolved
+func resolveCoAuthors(client *remote.Client) error {
+	coAuthors := make([]string, 0)
+	errs := make([]error, 0)
+
+	for _, coAuthor := range viper.GetStringSlice("co-author") {
+		if _, _, err := util.ParseIdentity(coAuthor); err != nil {
+			errs = append(errs, fmt.Errorf("co-author: %w", err))
+			continue
+		}
+		coAuthors = append(coAuthors, coAuthor)
+	}
+
+	for _, login := range userLogins() {
+		if login == "" || login == "-" {
+			continue
+		}
+		if client == nil {
+			log.Debugf("skipping resolution of user login %q", login)
+			continue
+		}
+		identity, err := client.GetUserIdentity(login)
+		if err != nil {
+			// the trailer is informational, so fall back to the user-name/user-email trailer
+			log.Warnf("unable to resolve user login %q: %v", login, err)
+			continue
+		}
+		log.Debugf("resolved user login %q to %s", login, identity)
+		coAuthors = append(coAuthors, identity.String())
+	}
+
+	// the same identity may be given explicitly and resolved from a login
+	viper.Set("co-author", util.Unique(coAuthors))
+
+	return errors.Join(errs...)
+}
+
+func addCommitIdentityFlags(flagSet *pflag.FlagSet) {
+	flagSet.String("author", "", "explicit commit author `identity` (\"Name <email>\"); implies Git Data API")
+	flagSet.String("committer", "", "explicit committer `identity` (\"Name <email>\"); implies Git Data API")
+	flagSet.String("date", "", "explicit author and committer `date` (RFC 3339, git-style or @unix); implies Git Data API")
+}
+
+// buildIdentities returns explicit author and committer identities, if configured.
+// When only a date is given, the trailer user is used as author.
+func buildIdentities() (author, committer *remote.Identity, err error) {
+	var date *time.Time
+	if dateSpec := viper.GetString("date"); dateSpec != "" {
+		t, err := util.ParseDate(dateSpec)
+		if err != nil {
+			return nil, nil, err
+		}
+		date = &t
+	}
+
+	if authorSpec := viper.GetString("author"); authorSpec != "" {
+		name, email, err := util.ParseIdentity(authorSpec)
+		if err != nil {
+			return nil, nil, fmt.Errorf("author: %w", err)
+		}
+		author = &remote.Identity{Name: name, Email: email, Date: date}
+	} else if date != nil {
+		name, email := viper.GetString("user-name"), viper.GetString("user-email")
+		if name == "" || email == "" {
+			return nil, nil, errors.New("--date requires --author or --user-name and --user-email")
+		}
+		author = &remote.Identity{Name: name, Email: email, Date: date}
+	}
+
+	if committerSpec := viper.GetString("committer"); committerSpec != "" {
+		name, email, err := util.ParseIdentity(committerSpec)
+		if err != nil {
+			return nil, nil, fmt.Errorf("committer: %w", err)
+		}
+		committer = &remote.Identity{Name: name, Email: email, Date: date}
+	}
+
+	return author, committer, nil
+}
+
 func addPullRequestFlags(flagSet *pflag.FlagSet) {
 	flagSet.String("pr-title", "", "pull request title")
-	flagSet.String("pr-body", "", "pull request body")
+	flagSet.String("pr-repo", "", "base `owner/repo` of pull requests from a fork (default: target repository)")
+	addPullRequestBodyFlags(flagSet, "pr-")
+	flagSet.Bool("pr-template", false, "render pull request body as a Go template, defaulting to the repository pull request template")
 	flagSet.Bool("pr-draft", false, "create pull request in draft mode")
 
-	// Create choice flag for auto-merge
-	autoMergeFlag := choiceflag.NewChoiceFlag(remote.GetAutoMergeChoices())
-	_ = autoMergeFlag.Set(remote.AutoMergeOff) // Set default to "off"
-	flagSet.VarP(autoMergeFlag, "pr-auto-merge", "", "auto-merge method for pull request")
+	addAutoMergeFlag(flagSet, "pr-auto-merge")
 
 	flagSet.Bool("pr-update", false, "update existing pull request fields")
+
+	updateBranchFlag := choiceflag.NewChoiceFlag(remote.GetUpdateBranchChoices())
+	_ = updateBranchFlag.Set(remote.AutoMergeOff)
+	flagSet.Var(updateBranchFlag, "pr-update-branch", "update pull request branch with its base, if behind, by method")
+
+	mergeFlag := choiceflag.NewChoiceFlag(remote.GetMergeChoices())
+	_ = mergeFlag.Set(remote.AutoMergeOff)
+	flagSet.Var(mergeFlag, "pr-merge", "merge pull request immediately, if mergeable, with method")
+	flagSet.Bool("pr-enqueue", false, "add pull request to the merge queue, once mergeable")
+	flagSet.Duration("pr-merge-retry", 0, "retry merge or enqueue for up to `duration` while checks are pending")
+	flagSet.Bool("pr-delete-branch", false, "delete head branch after merging pull request")
+
+	addPullRequestMetadataFlags(flagSet, "pr-")
+}
+
+// addPullRequestBodyFlags adds pull request body flags, with names given a prefix; see pullRequestBody
+func addPullRequestBodyFlags(flagSet *pflag.FlagSet, prefix string) {
+	flagSet.String(prefix+"body", "", "pull request body")
+	flagSet.String(prefix+"body-file", "", "read pull request body from `file`")
+}
+
+// addPullRequestMetadataFlags adds pull request reviewer, assignee, label and milestone flags,
+// with names given a prefix; see pullRequestMetadata
+func addPullRequestMetadataFlags(flagSet *pflag.FlagSet, prefix string) {
+	flagSet.StringSlice(prefix+"reviewer", []string{}, "request pull request review from `user` or org/team")
+	flagSet.StringSlice(prefix+"assignee", []string{}, "assign pull request to `user`")
+	flagSet.StringSlice(prefix+"label", []string{}, "apply `label` to pull request")
+	flagSet.String(prefix+"milestone", "", "add pull request to milestone `title` or number")
+	flagSet.Bool(prefix+"create-labels", false, "create missing pull request labels")
 }
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	"github.com/nexthink-oss/ghup/internal/notify"
+)
+
+func addNotifyFlags(flagSet *pflag.FlagSet) {
+	flagSet.StringSlice("on-success", nil, "`command` or webhook URL to receive JSON output on success")
+	flagSet.StringSlice("on-failure", nil, "`command` or webhook URL to receive JSON output on failure")
+	flagSet.String("hook-secret", "", "`secret` for HMAC-SHA256 signature of webhook payloads")
+	flagSet.Duration("hook-timeout", 30*time.Second, "`timeout` for each notification hook")
+}
+
+// withNotifyHooks wraps a command's run function so that its outcome is delivered to any configured
+// notification hooks, including errors returned before any output was written
+func withNotifyHooks(run func(cmd *cobra.Command, args []string) error) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		reportedOutput = nil
+
+		err := run(cmd, args)
+
+		output := reportedOutput
+		if output == nil {
+			if err == nil {
+				return nil
+			}
+			failure := &errorOutput{}
+			if !hasRepoSelectors() {
+				failure.Repository = fmt.Sprintf("%s/%s", viper.GetString("owner"), viper.GetString("repo"))
+			}
+			failure.SetError(err)
+			output = failure
+		}
+
+		runNotifyHooks(cmd, output)
+
+		return err
+	}
+}
+
+// runNotifyHooks delivers the command output to any configured success or failure hooks.
+// Hook failures are logged separately and do not affect the command result.
+func runNotifyHooks(cmd *cobra.Command, output CommandOutput) {
+	if cmd.Flags().Lookup("on-success") == nil {
+		return // command does not support notification hooks
+	}
+
+	event := notify.EventSuccess
+	if output.GetError() != nil {
+		event = notify.EventFailure
+	}
+
+	targets := viper.GetStringSlice("on-" + event)
+	if len(targets) == 0 {
+		return
+	}
+
+	// hooks always receive JSON, regardless of output format
+	payload, err := json.Marshal(output)
+	if err != nil {
+		log.Errorf("encoding %s hook payload: %v", event, err)
+		return
+	}
+
+	notifier := &notify.Notifier{
+		Targets: targets,
+		Secret:  viper.GetString("hook-secret"),
+		Timeout: viper.GetDuration("hook-timeout"),
+	}
+
+	for _, err := range notifier.Notify(cmd.Context(), event, cmd.Name(), payload) {
+		log.Errorf("%v", err)
+	}
+}
@@ -0,0 +1,54 @@
+//go:build acceptance
+
+package cmd_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAccNotifyHooksEarlyFailure(t *testing.T) {
+	setupTestEnvironment(t)
+
+	tmpDir := t.TempDir()
+	payloadFile := filepath.Join(tmpDir, "payload.json")
+
+	// an invalid separator fails the command before any output is written
+	args := []string{
+		"content", "-vvvv",
+		"--separator", "",
+		"--on-failure", "cat > " + payloadFile,
+	}
+
+	stdout, stderr, err := testExecuteCmd(t, testCmdSpec{Args: args})
+	if os.Getenv("TEST_GHUP_LOG_OUTPUT") != "" {
+		t.Logf("stdout:\n%s", stdout.String())
+		t.Logf("stderr:\n%s", stderr.String())
+	}
+	if err == nil {
+		t.Fatal("expected error for invalid separator")
+	}
+
+	content, err := os.ReadFile(payloadFile)
+	if err != nil {
+		t.Fatalf("on-failure hook did not run: %v", err)
+	}
+
+	var payload struct {
+		Repository string `json:"repository"`
+		Error      string `json:"error"`
+	}
+	if err := json.Unmarshal(content, &payload); err != nil {
+		t.Fatalf("failed to unmarshal hook payload: %v", err)
+	}
+
+	if !strings.Contains(payload.Error, "separator") {
+		t.Errorf("expected hook payload error to mention the separator, got %q", payload.Error)
+	}
+	if payload.Repository != os.Getenv("TEST_GHUP_OWNER")+"/"+os.Getenv("TEST_GHUP_REPO") {
+		t.Errorf("unexpected hook payload repository %q", payload.Repository)
+	}
+}
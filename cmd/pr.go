@@ -0,0 +1,684 @@
+package cmd
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/shurcooL/githubv4"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	"github.com/nexthink-oss/ghup/internal/remote"
+	"github.com/nexthink-oss/ghup/pkg/choiceflag"
+)
+
+// Exit codes distinguishing the outcomes of waiting for a pull request other than merged
+const (
+	exitPullRequestClosed = 2
+	exitChecksFailed      = 3
+	exitWaitTimedOut      = 4
+	exitDequeued          = 5
+)
+
+const (
+	defaultPullRequestWait         = 30 * time.Minute
+	defaultPullRequestWaitInterval = 15 * time.Second
+)
+
+type PullRequestOutput struct {
+	Repository   string              `json:"repository" yaml:"repository"`
+	PullRequest  *remote.PullRequest `json:"pullrequest,omitempty" yaml:"pullrequest,omitempty"`
+	Error        error               `json:"-" yaml:"-"`
+	ErrorMessage string              `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+func (o *PullRequestOutput) GetError() error {
+	return o.Error
+}
+
+func (o *PullRequestOutput) SetError(err error) {
+	o.Error = err
+	if err != nil {
+		o.ErrorMessage = err.Error()
+	}
+}
+
+type PullRequestListOutput struct {
+	Repository   string                `json:"repository" yaml:"repository"`
+	PullRequests []*remote.PullRequest `json:"pullrequests" yaml:"pullrequests"`
+	Error        error                 `json:"-" yaml:"-"`
+	ErrorMessage string                `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+func (o *PullRequestListOutput) GetError() error {
+	return o.Error
+}
+
+func (o *PullRequestListOutput) SetError(err error) {
+	o.Error = err
+	if err != nil {
+		o.ErrorMessage = err.Error()
+	}
+}
+
+// pullRequestAction acts upon a pull request, to which its current status has been applied
+type pullRequestAction func(client *remote.Client, pullRequest *remote.PullRequest) error
+
+func cmdPullRequest() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "pr",
+		Aliases: []string{"pull-request"},
+		Short:   "Manage pull requests.",
+	}
+
+	cmd.AddCommand(
+		cmdPullRequestOpen(),
+		cmdPullRequestUpdate(),
+		cmdPullRequestClose(),
+		cmdPullRequestReopen(),
+		cmdPullRequestMerge(),
+		cmdPullRequestEnqueue(),
+		cmdPullRequestUpdateBranch(),
+		cmdPullRequestDraft(false),
+		cmdPullRequestDraft(true),
+		cmdPullRequestStatus(),
+		cmdPullRequestList(),
+		cmdPullRequestWait(),
+	)
+
+	return cmd
+}
+
+func cmdPullRequestOpen() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "open [flags]",
+		Short: "Open a pull request from an existing branch.",
+		Long: `Open a pull request from an existing head branch, or report the pull request
+already open from head to base.`,
+		Args: cobra.NoArgs,
+		RunE: runPullRequestOpenCmd,
+	}
+
+	flags := cmd.Flags()
+	flags.String("head", localRepo.Branch, "head branch `name`")
+	flags.String("base", "", `base branch `+"`name`"+` (default: "[remote-default-branch]")`)
+	flags.String("title", "", "pull request title")
+	addPullRequestBodyFlags(flags, "")
+	flags.Bool("draft", false, "open pull request in draft mode")
+	addAutoMergeFlag(flags, "auto-merge")
+	addPullRequestMetadataFlags(flags, "")
+
+	flags.SetNormalizeFunc(normalizeFlags)
+	flags.SortFlags = false
+
+	cmd.MarkFlagsMutuallyExclusive("body", "body-file")
+
+	return cmd
+}
+
+func runPullRequestOpenCmd(cmd *cobra.Command, args []string) error {
+	head := viper.GetString("head")
+	if head == "" {
+		return errors.New("head branch required")
+	}
+
+	title := viper.GetString("title")
+	if title == "" {
+		return errors.New("title required")
+	}
+
+	body, err := pullRequestBody("")
+	if err != nil {
+		return err
+	}
+
+	client, output, err := newPullRequestOutput(cmd)
+	if err != nil {
+		return err
+	}
+
+	repoInfo, err := client.GetRepositoryInfo("")
+	if err != nil {
+		output.SetError(fmt.Errorf("GetRepositoryInfo(%s): %w", output.Repository, err))
+		return cmdOutput(cmd, output)
+	}
+
+	output.PullRequest = &remote.PullRequest{
+		RepoId:        repoInfo.NodeID,
+		Head:          head,
+		Base:          cmp.Or(viper.GetString("base"), repoInfo.DefaultBranch.Name),
+		Title:         title,
+		Body:          body,
+		Draft:         viper.GetBool("draft"),
+		AutoMergeMode: repoInfo.EffectiveAutoMergeMode(viper.GetString("auto-merge")),
+	}
+
+	found, err := client.FindPullRequestUrl(output.PullRequest)
+	if err != nil {
+		output.SetError(fmt.Errorf("searching open pull requests: %w", err))
+		return cmdOutput(cmd, output)
+	}
+
+	if found {
+		log.Infof("pull request already open: %s", output.PullRequest.Url)
+		var draft *bool
+		if cmd.Flags().Changed("draft") {
+			draft = new(viper.GetBool("draft"))
+		}
+		output.SetError(updateOpenPullRequest(client, output.PullRequest, draft))
+		return cmdOutput(cmd, output)
+	}
+
+	log.Debugf("opening pull request from %q to %q", output.PullRequest.Head, output.PullRequest.Base)
+	if err := client.CreatePullRequestV4(output.PullRequest); err != nil {
+		output.SetError(fmt.Errorf("opening pull request: %w", err))
+		return cmdOutput(cmd, output)
+	}
+
+	output.SetError(applyPullRequestMetadata(client, output.PullRequest, pullRequestMetadata("")))
+
+	return cmdOutput(cmd, output)
+}
+
+// updateOpenPullRequest applies the draft state, if given, and any metadata to a pull request already open;
+// its title, body and auto-merge state are left to `pr update`
+func updateOpenPullRequest(client *remote.Client, pullRequest *remote.PullRequest, draft *bool) error {
+	if draft != nil && *draft != pullRequest.Draft {
+		log.Infof("setting draft state of pull request #%d to %v", pullRequest.Number, *draft)
+		if err := client.SetPullRequestDraftV4(pullRequest, *draft); err != nil {
+			return err
+		}
+	}
+
+	return applyPullRequestMetadata(client, pullRequest, pullRequestMetadata(""))
+}
+
+func cmdPullRequestUpdate() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "update [flags] <number>",
+		Short: "Update the title, body, auto-merge and metadata of a pull request.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPullRequestAction(cmd, args[0], updatePullRequest)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.String("title", "", "pull request title (default: unchanged)")
+	addPullRequestBodyFlags(flags, "")
+	flags.Var(choiceflag.NewChoiceFlag(remote.GetAutoMergeChoices()), "auto-merge", "auto-merge method for pull request (default: unchanged)")
+	addPullRequestMetadataFlags(flags, "")
+
+	flags.SetNormalizeFunc(normalizeFlags)
+	flags.SortFlags = false
+
+	cmd.MarkFlagsMutuallyExclusive("body", "body-file")
+
+	return cmd
+}
+
+func updatePullRequest(client *remote.Client, pullRequest *remote.PullRequest) error {
+	body, err := pullRequestBody("")
+	if err != nil {
+		return err
+	}
+
+	pullRequest.Title = cmp.Or(viper.GetString("title"), pullRequest.Title)
+	pullRequest.Body = body // an empty body leaves the existing body unchanged
+
+	// auto-merge is reconciled only if a mode is given, otherwise remaining in its current mode
+	switch mode := viper.GetString("auto-merge"); mode {
+	case "":
+	case remote.AutoMergeOff:
+		pullRequest.AutoMergeMode = mode
+	default:
+		repoInfo, err := client.GetRepositoryInfo("")
+		if err != nil {
+			return fmt.Errorf("GetRepositoryInfo(): %w", err)
+		}
+		pullRequest.AutoMergeMode = repoInfo.EffectiveAutoMergeMode(mode)
+	}
+
+	log.Infof("updating pull request #%d", pullRequest.Number)
+	if err := client.UpdatePullRequestV4(pullRequest); err != nil {
+		return fmt.Errorf("updating pull request: %w", err)
+	}
+
+	return applyPullRequestMetadata(client, pullRequest, pullRequestMetadata(""))
+}
+
+func cmdPullRequestClose() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "close [flags] <number>",
+		Short: "Close a pull request without merging.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPullRequestAction(cmd, args[0], closePullRequest)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.String("comment", "", "comment explaining why the pull request is closed")
+	flags.Bool("delete-branch", false, "delete head branch after closing")
+
+	flags.SetNormalizeFunc(normalizeFlags)
+	flags.SortFlags = false
+
+	return cmd
+}
+
+func closePullRequest(client *remote.Client, pullRequest *remote.PullRequest) error {
+	if pullRequest.State == "open" {
+		log.Infof("closing pull request #%d", pullRequest.Number)
+		if err := client.ClosePullRequestV4(pullRequest, viper.GetString("comment")); err != nil {
+			return err
+		}
+		pullRequest.State = "closed"
+	} else {
+		log.Infof("pull request #%d already %s", pullRequest.Number, pullRequest.State)
+	}
+
+	if viper.GetBool("delete-branch") {
+		if err := client.DeleteRef(fmt.Sprintf("refs/heads/%s", pullRequest.Head)); err != nil {
+			return fmt.Errorf("deleting head branch: %w", err)
+		}
+		pullRequest.HeadDeleted = true
+	}
+
+	return nil
+}
+
+func cmdPullRequestReopen() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reopen <number>",
+		Short: "Reopen a closed pull request.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPullRequestAction(cmd, args[0], reopenPullRequest)
+		},
+	}
+
+	return cmd
+}
+
+func reopenPullRequest(client *remote.Client, pullRequest *remote.PullRequest) error {
+	switch pullRequest.State {
+	case "open":
+		log.Infof("pull request #%d already open", pullRequest.Number)
+		return nil
+	case "merged":
+		return fmt.Errorf("pull request #%d already merged", pullRequest.Number)
+	}
+
+	log.Infof("reopening pull request #%d", pullRequest.Number)
+	if err := client.ReopenPullRequestV4(pullRequest); err != nil {
+		return err
+	}
+	pullRequest.State = "open"
+
+	return nil
+}
+
+func cmdPullRequestMerge() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "merge [flags] <number>",
+		Short: "Merge a pull request, if mergeable.",
+		Long: `Merge a pull request, if mergeable, optionally retrying while mergeability and checks are pending.
+With method 'auto', or if the repository does not allow the given method, the first allowed
+of merge, squash and rebase is used.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPullRequestAction(cmd, args[0], mergePullRequest)
+		},
+	}
+
+	flags := cmd.Flags()
+	methodFlag := choiceflag.NewChoiceFlag(remote.GetMergeChoices()[1:]) // excluding "off"
+	_ = methodFlag.Set(remote.MergeAuto)
+	flags.Var(methodFlag, "method", "merge method")
+	flags.Duration("retry", 0, "retry merge for up to `duration` while checks are pending")
+	flags.Duration("interval", defaultPullRequestWaitInterval, "retry `interval`")
+	flags.Bool("delete-branch", false, "delete head branch after merging")
+
+	flags.SetNormalizeFunc(normalizeFlags)
+	flags.SortFlags = false
+
+	return cmd
+}
+
+func mergePullRequest(client *remote.Client, pullRequest *remote.PullRequest) error {
+	repoInfo, err := client.GetRepositoryInfo("")
+	if err != nil {
+		return fmt.Errorf("GetRepositoryInfo(): %w", err)
+	}
+
+	method, err := repoInfo.SelectMergeMethod(viper.GetString("method"))
+	if err != nil {
+		return err
+	}
+
+	return client.MergePullRequest(pullRequest, method, viper.GetDuration("retry"), viper.GetDuration("interval"), viper.GetBool("delete-branch"))
+}
+
+func cmdPullRequestEnqueue() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "enqueue [flags] <number>",
+		Short: "Add a pull request to the merge queue, if mergeable.",
+		Long: `Add a pull request to the merge queue of its base branch, if mergeable, optionally retrying
+while mergeability and checks are pending. A pull request already queued is left in place.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPullRequestAction(cmd, args[0], func(client *remote.Client, pullRequest *remote.PullRequest) error {
+				return client.EnqueuePullRequest(pullRequest, viper.GetDuration("retry"), viper.GetDuration("interval"), viper.GetBool("jump"))
+			})
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.Duration("retry", 0, "retry for up to `duration` while checks are pending")
+	flags.Duration("interval", defaultPullRequestWaitInterval, "retry `interval`")
+	flags.Bool("jump", false, "jump to the front of the queue")
+
+	flags.SetNormalizeFunc(normalizeFlags)
+	flags.SortFlags = false
+
+	return cmd
+}
+
+func cmdPullRequestUpdateBranch() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "update-branch [flags] <number>",
+		Short: "Update a pull request branch with its base branch, if behind.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPullRequestAction(cmd, args[0], func(client *remote.Client, pullRequest *remote.PullRequest) error {
+				return client.UpdatePullRequestBranch(pullRequest, viper.GetString("method"), client)
+			})
+		},
+	}
+
+	flags := cmd.Flags()
+	methodFlag := choiceflag.NewChoiceFlag(remote.GetUpdateBranchChoices()[1:]) // excluding "off"
+	_ = methodFlag.Set(remote.AutoMergeMerge)
+	flags.Var(methodFlag, "method", "update method")
+
+	flags.SetNormalizeFunc(normalizeFlags)
+	flags.SortFlags = false
+
+	return cmd
+}
+
+// cmdPullRequestDraft returns the `draft` command, converting pull requests to drafts,
+// or the `ready` command, marking them ready for review
+func cmdPullRequestDraft(draft bool) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ready <number>",
+		Short: "Mark a draft pull request ready for review.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPullRequestAction(cmd, args[0], func(client *remote.Client, pullRequest *remote.PullRequest) error {
+				if pullRequest.Draft == draft {
+					log.Infof("pull request #%d unchanged", pullRequest.Number)
+					return nil
+				}
+				return client.SetPullRequestDraftV4(pullRequest, draft)
+			})
+		},
+	}
+
+	if draft {
+		cmd.Use = "draft <number>"
+		cmd.Short = "Convert a pull request to a draft."
+	}
+
+	return cmd
+}
+
+func cmdPullRequestStatus() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status <number>",
+		Short: "Report the state, mergeability, review decision and checks of a pull request.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPullRequestAction(cmd, args[0], func(*remote.Client, *remote.PullRequest) error {
+				return nil
+			})
+		},
+	}
+
+	return cmd
+}
+
+func cmdPullRequestList() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list [flags]",
+		Short: "List pull requests, most recently updated first.",
+		Args:  cobra.NoArgs,
+		RunE:  runPullRequestListCmd,
+	}
+
+	flags := cmd.Flags()
+	flags.String("head", "", "head branch `name`")
+	flags.String("base", "", "base branch `name`")
+	flags.StringSlice("label", []string{}, "pull request `label` (any of)")
+	stateFlag := choiceflag.NewChoiceFlag([]string{"open", "closed", "merged", "all"})
+	_ = stateFlag.Set("open")
+	flags.Var(stateFlag, "state", "pull request state")
+
+	flags.SetNormalizeFunc(normalizeFlags)
+	flags.SortFlags = false
+
+	return cmd
+}
+
+func runPullRequestListCmd(cmd *cobra.Command, args []string) error {
+	filter := remote.PullRequestFilter{
+		Head:   viper.GetString("head"),
+		Base:   viper.GetString("base"),
+		Labels: viper.GetStringSlice("label"),
+	}
+	if state := viper.GetString("state"); state != "all" {
+		filter.States = []githubv4.PullRequestState{githubv4.PullRequestState(strings.ToUpper(state))}
+	}
+
+	repo := remote.Repo{
+		Owner: viper.GetString("owner"),
+		Name:  viper.GetString("repo"),
+	}
+
+	client, err := remote.NewClient(cmd.Context(), &repo)
+	if err != nil {
+		return fmt.Errorf("NewClient(%s): %w", repo, err)
+	}
+
+	output := &PullRequestListOutput{
+		Repository: repo.String(),
+	}
+
+	output.PullRequests, err = client.ListPullRequests(filter)
+	output.SetError(err)
+
+	return cmdOutput(cmd, output)
+}
+
+func cmdPullRequestWait() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "wait [flags] <number>",
+		Short: "Wait for a pull request to be merged or closed.",
+		Long: `Wait for a pull request to be merged or closed, polling its state and status checks.
+A pull request in the merge queue is instead awaited until merged or removed from the queue.
+Exits 0 if merged, 2 if closed without merging, 3 if checks failed, 4 if timed out
+and 5 if removed from the merge queue.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPullRequestAction(cmd, args[0], func(client *remote.Client, pullRequest *remote.PullRequest) error {
+				return waitForPullRequest(client, pullRequest, viper.GetDuration("timeout"), viper.GetDuration("interval"))
+			})
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.Duration("timeout", defaultPullRequestWait, "maximum `duration` to wait")
+	flags.Duration("interval", defaultPullRequestWaitInterval, "polling `interval`")
+
+	flags.SetNormalizeFunc(normalizeFlags)
+	flags.SortFlags = false
+
+	return cmd
+}
+
+// newPullRequestOutput returns a client for, and an output reporting on, the target repository
+func newPullRequestOutput(cmd *cobra.Command) (*remote.Client, *PullRequestOutput, error) {
+	repo := remote.Repo{
+		Owner: viper.GetString("owner"),
+		Name:  viper.GetString("repo"),
+	}
+
+	client, err := remote.NewClient(cmd.Context(), &repo)
+	if err != nil {
+		return nil, nil, fmt.Errorf("NewClient(%s): %w", repo, err)
+	}
+
+	return client, &PullRequestOutput{Repository: repo.String()}, nil
+}
+
+// runPullRequestAction runs an action upon the pull request with the given number, reporting its resulting state
+func runPullRequestAction(cmd *cobra.Command, arg string, action pullRequestAction) error {
+	number, err := parsePullRequestNumber(arg)
+	if err != nil {
+		return err
+	}
+
+	client, output, err := newPullRequestOutput(cmd)
+	if err != nil {
+		return err
+	}
+
+	output.PullRequest = &remote.PullRequest{Number: number}
+
+	status, err := client.GetPullRequestStatus(number)
+	if err != nil {
+		output.SetError(err)
+		return cmdOutput(cmd, output)
+	}
+	status.Apply(output.PullRequest)
+
+	output.SetError(action(client, output.PullRequest))
+
+	return cmdOutput(cmd, output)
+}
+
+// parsePullRequestNumber parses a pull request number, with or without a leading '#'
+func parsePullRequestNumber(arg string) (int, error) {
+	number, err := strconv.Atoi(strings.TrimPrefix(arg, "#"))
+	if err != nil || number < 1 {
+		return 0, fmt.Errorf("invalid pull request number %q", arg)
+	}
+	return number, nil
+}
+
+// addAutoMergeFlag adds an auto-merge method choice flag, defaulting to "off"
+func addAutoMergeFlag(flagSet *pflag.FlagSet, name string) {
+	autoMergeFlag := choiceflag.NewChoiceFlag(remote.GetAutoMergeChoices())
+	_ = autoMergeFlag.Set(remote.AutoMergeOff)
+	flagSet.Var(autoMergeFlag, name, "auto-merge method for pull request")
+}
+
+// addPullRequestWaitFlags adds --pr-wait[=timeout] and --pr-wait-interval
+func addPullRequestWaitFlags(flagSet *pflag.FlagSet) {
+	flagSet.Duration("pr-wait", 0, "wait up to `timeout` for the pull request to be merged or closed")
+	flagSet.Lookup("pr-wait").NoOptDefVal = defaultPullRequestWait.String()
+	flagSet.Duration("pr-wait-interval", defaultPullRequestWaitInterval, "pull request polling `interval`")
+}
+
+// pullRequestBody returns the pull request body given by the [prefix]body or [prefix]body-file flag
+func pullRequestBody(prefix string) (string, error) {
+	bodyFile := viper.GetString(prefix + "body-file")
+	if bodyFile == "" {
+		return viper.GetString(prefix + "body"), nil
+	}
+
+	body, err := os.ReadFile(bodyFile)
+	if err != nil {
+		return "", fmt.Errorf("reading pull request body: %w", err)
+	}
+
+	return string(body), nil
+}
+
+// pullRequestMetadata returns the pull request metadata given by the [prefix]reviewer, [prefix]assignee,
+// [prefix]label, [prefix]milestone and [prefix]create-labels flags
+func pullRequestMetadata(prefix string) *remote.PullRequestMetadata {
+	return &remote.PullRequestMetadata{
+		Reviewers:    viper.GetStringSlice(prefix + "reviewer"),
+		Assignees:    viper.GetStringSlice(prefix + "assignee"),
+		Labels:       viper.GetStringSlice(prefix + "label"),
+		Milestone:    viper.GetString(prefix + "milestone"),
+		CreateLabels: viper.GetBool(prefix + "create-labels"),
+	}
+}
+
+// applyPullRequestMetadata applies any given metadata to a pull request, recording it for output
+func applyPullRequestMetadata(client *remote.Client, pullRequest *remote.PullRequest, metadata *remote.PullRequestMetadata) error {
+	if metadata.IsEmpty() {
+		return nil
+	}
+
+	if err := client.ApplyPullRequestMetadata(pullRequest, metadata); err != nil {
+		return fmt.Errorf("applying pull request metadata: %w", err)
+	}
+
+	pullRequest.Reviewers = metadata.Reviewers
+	pullRequest.Assignees = metadata.Assignees
+	pullRequest.Labels = metadata.Labels
+	pullRequest.Milestone = metadata.Milestone
+
+	return nil
+}
+
+// waitForPullRequest waits for a pull request to be merged or closed or, if queued, removed from the
+// merge queue, recording its final state, merge commit and the outcome; outcomes other than merged
+// are errors with distinct exit codes
+func waitForPullRequest(client *remote.Client, pullRequest *remote.PullRequest, timeout, interval time.Duration) error {
+	log.Infof("waiting up to %s for pull request #%d", timeout, pullRequest.Number)
+
+	outcome, status, err := client.WaitForPullRequest(pullRequest.Number, timeout, interval, pullRequest.QueueState != "")
+	if status != nil {
+		status.Apply(pullRequest)
+	}
+	if err != nil {
+		return fmt.Errorf("waiting for pull request #%d: %w", pullRequest.Number, err)
+	}
+
+	pullRequest.WaitOutcome = outcome
+	if outcome == remote.WaitMerged {
+		log.Infof("pull request #%d merged as %s", pullRequest.Number, status.MergeCommit)
+	}
+
+	return waitOutcomeError(pullRequest.Number, outcome, timeout)
+}
+
+// waitOutcomeError returns nil if a pull request merged, otherwise an error with the exit code of the outcome
+func waitOutcomeError(number int, outcome string, timeout time.Duration) error {
+	switch outcome {
+	case remote.WaitMerged:
+		return nil
+	case remote.WaitClosed:
+		return &ExitError{Code: exitPullRequestClosed, Err: fmt.Errorf("pull request #%d closed without merging", number)}
+	case remote.WaitChecksFailed:
+		return &ExitError{Code: exitChecksFailed, Err: fmt.Errorf("pull request #%d checks failed", number)}
+	case remote.WaitDequeued:
+		return &ExitError{Code: exitDequeued, Err: fmt.Errorf("pull request #%d removed from the merge queue", number)}
+	case remote.WaitTimedOut:
+		return &ExitError{Code: exitWaitTimedOut, Err: fmt.Errorf("timed out after %s waiting for pull request #%d", timeout, number)}
+	default:
+		return fmt.Errorf("unexpected wait outcome %q", outcome)
+	}
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/nexthink-oss/ghup/internal/remote"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{
+			name:     "Success",
+			expected: 0,
+		},
+		{
+			name:     "Plain error",
+			err:      errors.New("failed"),
+			expected: 1,
+		},
+		{
+			name:     "Exit error",
+			err:      &ExitError{Code: exitChecksFailed, Err: errors.New("checks failed")},
+			expected: exitChecksFailed,
+		},
+		{
+			name:     "Wrapped exit error",
+			err:      fmt.Errorf("waiting: %w", &ExitError{Code: exitDequeued, Err: errors.New("dequeued")}),
+			expected: exitDequeued,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := ExitCode(tt.err); code != tt.expected {
+				t.Errorf("ExitCode(%v) = %d; expected %d", tt.err, code, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWaitOutcomeError(t *testing.T) {
+	tests := []struct {
+		outcome      string
+		expectedCode int
+	}{
+		{outcome: remote.WaitMerged, expectedCode: 0},
+		{outcome: remote.WaitClosed, expectedCode: exitPullRequestClosed},
+		{outcome: remote.WaitChecksFailed, expectedCode: exitChecksFailed},
+		{outcome: remote.WaitTimedOut, expectedCode: exitWaitTimedOut},
+		{outcome: remote.WaitDequeued, expectedCode: exitDequeued},
+		{outcome: "unknown", expectedCode: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.outcome, func(t *testing.T) {
+			err := waitOutcomeError(7, tt.outcome, time.Minute)
+			if code := ExitCode(err); code != tt.expectedCode {
+				t.Errorf("waitOutcomeError(%q) = %v (exit code %d); expected exit code %d", tt.outcome, err, code, tt.expectedCode)
+			}
+		})
+	}
+}
+
+// TestPullRequestFlagValidation checks that invalid pr flags and arguments are rejected before any API call
+func TestPullRequestFlagValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		expectError string
+	}{
+		{
+			name:        "Open without head",
+			args:        []string{"pr", "open", "--head", "", "--title", "Title"},
+			expectError: "head branch required",
+		},
+		{
+			name:        "Open without title",
+			args:        []string{"pr", "open", "--head", "feature"},
+			expectError: "title required",
+		},
+		{
+			name:        "Open with body and body file",
+			args:        []string{"pr", "open", "--head", "feature", "--title", "Title", "--body", "body", "--body-file", "body.md"},
+			expectError: "[body body-file] were all set",
+		},
+		{
+			name:        "Open with invalid auto-merge method",
+			args:        []string{"pr", "open", "--head", "feature", "--title", "Title", "--auto-merge", "fast-forward"},
+			expectError: "invalid argument",
+		},
+		{
+			name:        "Close with invalid number",
+			args:        []string{"pr", "close", "#0"},
+			expectError: `invalid pull request number "#0"`,
+		},
+		{
+			name:        "Merge without number",
+			args:        []string{"pr", "merge"},
+			expectError: "accepts 1 arg(s)",
+		},
+	}
+
+	t.Setenv("GHUP_TOKEN", "test-token")
+	t.Setenv("GHUP_OWNER", "owner")
+	t.Setenv("GHUP_REPO", "repo")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+
+			cmd := New()
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+
+			err := cmd.Execute()
+			if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+				t.Errorf("pr %v error = %v; expected %q", tt.args, err, tt.expectError)
+			}
+			if code := ExitCode(err); code != 1 {
+				t.Errorf("pr %v exit code = %d; expected 1", tt.args, code)
+			}
+		})
+	}
+}
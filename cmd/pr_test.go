@@ -0,0 +1,122 @@
+//go:build acceptance
+
+package cmd_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/nexthink-oss/ghup/cmd"
+)
+
+// testPullRequestCmd runs a pr command, decoding its output into output
+func testPullRequestCmd(t *testing.T, output any, args ...string) {
+	t.Helper()
+
+	stdout, stderr, err := testExecuteCmd(t, testCmdSpec{Args: append([]string{"pr", "-vvvv"}, args...)})
+	if os.Getenv("TEST_GHUP_LOG_OUTPUT") != "" {
+		t.Logf("stdout:\n%s", stdout.String())
+		t.Logf("stderr:\n%s", stderr.String())
+	}
+	if err != nil {
+		t.Fatalf("pr %v: unexpected error: %v", args, err)
+	}
+
+	if err := json.Unmarshal(stdout.Bytes(), output); err != nil {
+		t.Fatalf("pr %v: failed to unmarshal JSON output: %v", args, err)
+	}
+}
+
+// testPullRequestBranch creates branch with a commit on top of base, ready to be the head of a pull request
+func testPullRequestBranch(t *testing.T, branch, base string) {
+	t.Helper()
+
+	file := filepath.Join(t.TempDir(), "change.txt")
+	if err := os.WriteFile(file, []byte(branch), 0o600); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	args := []string{"content", "--branch", branch, "--update", file + ":test-path/" + branch + ".txt"}
+	if base != "" {
+		args = append(args, "--base-branch", base)
+	}
+	if _, _, err := testExecuteCmd(t, testCmdSpec{Args: args}); err != nil {
+		t.Fatalf("creating branch %q: %v", branch, err)
+	}
+}
+
+func TestAccPullRequestOpenListClose(t *testing.T) {
+	_, resources := setupTestResources(t)
+
+	branch := "test-pr-" + testRandomString(8)
+	resources.AddBranch(branch)
+	testPullRequestBranch(t, branch, "")
+
+	var opened cmd.PullRequestOutput
+	testPullRequestCmd(t, &opened, "open", "--head", branch, "--title", "Test pr open")
+	if opened.PullRequest == nil || opened.PullRequest.Number == 0 {
+		t.Fatalf("expected a pull request to be opened, got %+v", opened)
+	}
+	number := strconv.Itoa(opened.PullRequest.Number)
+
+	var reopened cmd.PullRequestOutput
+	testPullRequestCmd(t, &reopened, "open", "--head", branch, "--title", "Test pr open")
+	if reopened.PullRequest == nil || reopened.PullRequest.Number != opened.PullRequest.Number {
+		t.Errorf("expected pull request #%s to be reported, got %+v", number, reopened.PullRequest)
+	}
+
+	var listed cmd.PullRequestListOutput
+	testPullRequestCmd(t, &listed, "list", "--head", branch)
+	if len(listed.PullRequests) != 1 || listed.PullRequests[0].Number != opened.PullRequest.Number {
+		t.Errorf("expected open pull request #%s to be listed, got %+v", number, listed.PullRequests)
+	}
+
+	var closed cmd.PullRequestOutput
+	testPullRequestCmd(t, &closed, "close", number, "--comment", "Closed by acceptance test")
+	if closed.PullRequest == nil || closed.PullRequest.State != "closed" {
+		t.Errorf("expected pull request #%s to be closed, got %+v", number, closed.PullRequest)
+	}
+
+	var open cmd.PullRequestListOutput
+	testPullRequestCmd(t, &open, "list", "--head", branch)
+	if len(open.PullRequests) != 0 {
+		t.Errorf("expected no open pull requests, got %+v", open.PullRequests)
+	}
+
+	var all cmd.PullRequestListOutput
+	testPullRequestCmd(t, &all, "list", "--head", branch, "--state", "closed")
+	if len(all.PullRequests) != 1 || all.PullRequests[0].Number != opened.PullRequest.Number {
+		t.Errorf("expected closed pull request #%s to be listed, got %+v", number, all.PullRequests)
+	}
+}
+
+func TestAccPullRequestMerge(t *testing.T) {
+	_, resources := setupTestResources(t)
+
+	// merge into a scratch base branch, so as to leave the default branch untouched
+	base := "test-pr-base-" + testRandomString(8)
+	head := "test-pr-merge-" + testRandomString(8)
+	resources.AddBranch(base)
+	resources.AddBranch(head)
+	testPullRequestBranch(t, base, "")
+	testPullRequestBranch(t, head, base)
+
+	var opened cmd.PullRequestOutput
+	testPullRequestCmd(t, &opened, "open", "--head", head, "--base", base, "--title", "Test pr merge")
+	if opened.PullRequest == nil || opened.PullRequest.Number == 0 {
+		t.Fatalf("expected a pull request to be opened, got %+v", opened)
+	}
+	number := strconv.Itoa(opened.PullRequest.Number)
+
+	var merged cmd.PullRequestOutput
+	testPullRequestCmd(t, &merged, "merge", number, "--retry", "1m", "--interval", "5s", "--delete-branch")
+	if merged.PullRequest == nil || merged.PullRequest.State != "merged" || merged.PullRequest.MergeCommit == "" {
+		t.Errorf("expected pull request #%s to be merged, got %+v", number, merged.PullRequest)
+	}
+	if !merged.PullRequest.HeadDeleted {
+		t.Errorf("expected head branch %q to be deleted", head)
+	}
+}
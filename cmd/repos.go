@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	"github.com/nexthink-oss/ghup/internal/remote"
+)
+
+// fanOutAnnotation marks commands supporting repository selectors
+const fanOutAnnotation = "ghup/fan-out"
+
+var errRateLimited = errors.New("skipped: API rate limit reached")
+
+// RepositoriesOutput aggregates the per-repository outputs of a command run across repositories
+type RepositoriesOutput struct {
+	Repositories []*repositoryOutput `json:"repositories" yaml:"repositories"`
+	Succeeded    int                 `json:"succeeded" yaml:"succeeded"`
+	Failed       int                 `json:"failed" yaml:"failed"`
+	Error        error               `json:"-" yaml:"-"`
+	ErrorMessage string              `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+func (o *RepositoriesOutput) GetError() error {
+	return o.Error
+}
+
+func (o *RepositoriesOutput) SetError(err error) {
+	o.Error = err
+	if err != nil {
+		o.ErrorMessage = err.Error()
+	}
+}
+
+// repositoryOutput reports the usual output of a command for one of the selected repositories, if
+// any, along with the repository and any error, so that the usual output itself is unchanged
+type repositoryOutput struct {
+	Repository   string        `json:"repository" yaml:"repository"`
+	Output       CommandOutput `json:"output,omitempty" yaml:"output,omitempty"`
+	Error        error         `json:"-" yaml:"-"`
+	ErrorMessage string        `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+func (o *repositoryOutput) GetError() error {
+	return o.Error
+}
+
+func (o *repositoryOutput) SetError(err error) {
+	o.Error = err
+	if err != nil {
+		o.ErrorMessage = err.Error()
+	}
+}
+
+// errorOutput reports a command that failed before writing its output
+type errorOutput struct {
+	Repository   string `json:"repository,omitempty" yaml:"repository,omitempty"`
+	Error        error  `json:"-" yaml:"-"`
+	ErrorMessage string `json:"error" yaml:"error"`
+}
+
+func (o *errorOutput) GetError() error {
+	return o.Error
+}
+
+func (o *errorOutput) SetError(err error) {
+	o.Error = err
+	if err != nil {
+		o.ErrorMessage = err.Error()
+	}
+}
+
+// repoRunner runs a command against a single repository, reporting any error via its output
+type repoRunner func(ctx context.Context, repo remote.Repo) CommandOutput
+
+func addRepoSelectorFlags(flagSet *pflag.FlagSet) {
+	flagSet.StringSlice("repos", nil, "run against each repository `owner/name` (bare names use --owner)")
+	flagSet.String("repos-file", "", "run against each repository listed in `file`, one per line")
+	flagSet.String("repo-query", "", "run against each repository matching a GitHub search `query` (e.g. 'org:x topic:go')")
+	flagSet.Int("concurrency", 4, "maximum `number` of repositories processed concurrently")
+}
+
+// hasRepoSelectors reports whether any repository selector is set
+func hasRepoSelectors() bool {
+	return len(viper.GetStringSlice("repos")) > 0 || viper.GetString("repos-file") != "" || viper.GetString("repo-query") != ""
+}
+
+// supportsRepoSelectors reports whether a command may run across repositories
+func supportsRepoSelectors(cmd *cobra.Command) bool {
+	return cmd.Annotations[fanOutAnnotation] == "true"
+}
+
+// resolveRepos returns the repositories selected via --repos, --repos-file and --repo-query, in order and without duplicates
+func resolveRepos(ctx context.Context) ([]remote.Repo, error) {
+	defaultOwner := viper.GetString("owner")
+	specs := viper.GetStringSlice("repos")
+
+	if reposFile := viper.GetString("repos-file"); reposFile != "" {
+		fileSpecs, err := readReposFile(reposFile)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, fileSpecs...)
+	}
+
+	repos := make([]remote.Repo, 0, len(specs))
+	errs := make([]error, 0)
+
+	for _, spec := range specs {
+		repo, err := remote.ParseRepo(spec, defaultOwner)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		repos = append(repos, repo)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	if query := viper.GetString("repo-query"); query != "" {
+		client, err := remote.NewClient(ctx, &remote.Repo{})
+		if err != nil {
+			return nil, fmt.Errorf("NewClient(): %w", err)
+		}
+
+		found, err := client.SearchRepositories(query)
+		if err != nil {
+			return nil, err
+		}
+		log.Infof("repository query %q matched %d repositories", query, len(found))
+		repos = append(repos, found...)
+	}
+
+	seen := make(map[remote.Repo]struct{}, len(repos))
+	unique := make([]remote.Repo, 0, len(repos))
+	for _, repo := range repos {
+		if _, ok := seen[repo]; !ok {
+			seen[repo] = struct{}{}
+			unique = append(unique, repo)
+		}
+	}
+
+	if len(unique) == 0 {
+		return nil, errors.New("repository selectors matched no repositories")
+	}
+
+	return unique, nil
+}
+
+// readReposFile reads repository specs from a file, ignoring blank lines and '#' comments
+func readReposFile(path string) (specs []string, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening repos file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if line = strings.TrimSpace(line); line != "" {
+			specs = append(specs, line)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading repos file: %w", err)
+	}
+
+	return specs, nil
+}
+
+// runForRepos runs a command against the repository given by --owner/--repo or, if any
+// repository selectors are set, against each selected repository with bounded concurrency.
+// Once the API rate limit is exhausted, remaining repositories are skipped.
+func runForRepos(cmd *cobra.Command, run repoRunner) error {
+	ctx := cmd.Context()
+
+	if !hasRepoSelectors() {
+		repo := remote.Repo{
+			Owner: viper.GetString("owner"),
+			Name:  viper.GetString("repo"),
+		}
+		return cmdOutput(cmd, run(ctx, repo))
+	}
+
+	repos, err := resolveRepos(ctx)
+	if err != nil {
+		return err
+	}
+
+	output := &RepositoriesOutput{
+		Repositories: make([]*repositoryOutput, len(repos)),
+	}
+
+	var (
+		wg          sync.WaitGroup
+		mu          sync.Mutex
+		rateLimited bool
+	)
+
+	semaphore := make(chan struct{}, max(1, viper.GetInt("concurrency")))
+
+	for i, repo := range repos {
+		output.Repositories[i] = &repositoryOutput{Repository: repo.String()}
+		semaphore <- struct{}{}
+
+		mu.Lock()
+		skip := rateLimited
+		mu.Unlock()
+
+		if skip {
+			<-semaphore
+			output.Repositories[i].SetError(errRateLimited)
+			continue
+		}
+
+		wg.Go(func() {
+			defer func() { <-semaphore }()
+
+			log.Infof("processing repository %s", repo.String())
+			repoOutput := run(ctx, repo)
+			output.Repositories[i].Output = repoOutput
+			output.Repositories[i].SetError(repoOutput.GetError())
+
+			if err := repoOutput.GetError(); err != nil && remote.IsRateLimitError(err) {
+				mu.Lock()
+				rateLimited = true
+				mu.Unlock()
+			}
+		})
+	}
+
+	wg.Wait()
+
+	errs := make([]error, 0)
+	for i, repoOutput := range output.Repositories {
+		if err := repoOutput.GetError(); err != nil {
+			output.Failed++
+			errs = append(errs, fmt.Errorf("%s: %w", repos[i].String(), err))
+		} else {
+			output.Succeeded++
+		}
+	}
+	output.SetError(errors.Join(errs...))
+
+	return cmdOutput(cmd, output)
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -39,6 +40,9 @@ func cmdResolve() *cobra.Command {
 		Long:  `Resolve a commit-ish to a SHA, optionally finding matching branches and/or tags.`,
 		Args:  cobra.MaximumNArgs(1),
 		RunE:  runResolveCmd,
+		Annotations: map[string]string{
+			fanOutAnnotation: "true",
+		},
 	}
 
 	flags := cmd.Flags()
@@ -55,13 +59,6 @@ func cmdResolve() *cobra.Command {
 }
 
 func runResolveCmd(cmd *cobra.Command, args []string) (err error) {
-	ctx := cmd.Context()
-
-	repo := remote.Repo{
-		Owner: viper.GetString("owner"),
-		Name:  viper.GetString("repo"),
-	}
-
 	var commitish string
 	if len(args) == 1 {
 		commitish = args[0]
@@ -73,25 +70,34 @@ func runResolveCmd(cmd *cobra.Command, args []string) (err error) {
 		return fmt.Errorf("commitish is required")
 	}
 
+	return runForRepos(cmd, func(ctx context.Context, repo remote.Repo) CommandOutput {
+		return resolveRepo(ctx, repo, commitish)
+	})
+}
+
+func resolveRepo(ctx context.Context, repo remote.Repo, commitish string) *ResolveOutput {
+	output := &ResolveOutput{
+		Repository: repo.String(),
+		Commitish:  commitish,
+	}
+
 	client, err := remote.NewClient(ctx, &repo)
 	if err != nil {
-		return fmt.Errorf("NewClient(%s): %w", repo, err)
+		output.SetError(fmt.Errorf("NewClient(%s): %w", repo, err))
+		return output
 	}
 
 	sha, err := client.ResolveCommitish(commitish)
 	if err != nil {
-		return fmt.Errorf("ResolveCommitish(%q): %w", commitish, err)
+		output.SetError(fmt.Errorf("ResolveCommitish(%q): %w", commitish, err))
+		return output
 	}
 
-	output := &ResolveOutput{
-		Repository: repo.String(),
-		Commitish:  commitish,
-		SHA:        sha,
-	}
+	output.SHA = sha
 
 	if sha == "" {
 		output.SetError(errors.New("commitish does not exist"))
-		return cmdOutput(cmd, output)
+		return output
 	}
 
 	errs := make([]error, 0)
@@ -118,5 +124,5 @@ func runResolveCmd(cmd *cobra.Command, args []string) (err error) {
 		output.SetError(errors.Join(errs...))
 	}
 
-	return cmdOutput(cmd, output)
+	return output
 }
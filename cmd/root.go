@@ -20,10 +20,42 @@ type CommandOutput interface {
 	SetError(error)
 }
 
+// ExitError is an error calling for a specific process exit code
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the process exit code for the error returned by a command:
+// 0 on success, the code of an ExitError, or 1 otherwise
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+
+	return 1
+}
+
 var (
 	localRepo local.Repository
 
 	outputEncoder OutputEncoder
+
+	// reportedOutput is the output last written by cmdOutput, for notification hooks
+	reportedOutput CommandOutput
 )
 
 func New() *cobra.Command {
@@ -59,6 +91,7 @@ func New() *cobra.Command {
 	persistentFlags.String("token", "", "GitHub Token or path/to/token-file")
 	persistentFlags.StringP("owner", "o", localRepo.Owner, "repository owner `name`")
 	persistentFlags.StringP("repo", "r", localRepo.Name, "repository `name`")
+	addRepoSelectorFlags(persistentFlags)
 	persistentFlags.Bool("no-cli-token", false, "disable fallback to GitHub CLI Token")
 	persistentFlags.CountP("verbose", "v", "increase verbosity``")
 	persistentFlags.StringP("output-format", "O", "json", "output `format` (json|j, yaml|y)")
@@ -68,9 +101,11 @@ func New() *cobra.Command {
 	persistentFlags.SortFlags = false
 
 	cmd.AddCommand(
+		cmdComment(),
 		cmdContent(),
 		cmdDebug(),
 		cmdDeployment(),
+		cmdPullRequest(),
 		cmdResolve(),
 		cmdTag(),
 		cmdUpdateRef(),
@@ -87,5 +122,7 @@ func cmdOutput(cmd *cobra.Command, output CommandOutput) error {
 		cmd.SilenceErrors = true
 	}
 
+	reportedOutput = output
+
 	return errors.Join(outputErr, encodeErr)
 }
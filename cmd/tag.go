@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"cmp"
+	"context"
 	"errors"
 	"fmt"
 
@@ -39,7 +41,10 @@ func cmdTag() *cobra.Command {
 		Use:   "tag [flags] [<name>]",
 		Short: "Create or update lightweight or annotated tags.",
 		Args:  cobra.MaximumNArgs(1),
-		RunE:  runTagCmd,
+		RunE:  withNotifyHooks(runTagCmd),
+		Annotations: map[string]string{
+			fanOutAnnotation: "true",
+		},
 	}
 
 	flags := cmd.Flags()
@@ -50,7 +55,9 @@ func cmdTag() *cobra.Command {
 	_ = flags.MarkDeprecated("branch", "pass commitish via -c/--commitish instead")
 	_ = flags.MarkHidden("branch")
 	addCommitMessageFlags(flags)
+	addCommitIdentityFlags(flags)
 	addForceFlag(flags)
+	addNotifyFlags(flags)
 
 	flags.SetNormalizeFunc(normalizeFlags)
 	flags.SortFlags = false
@@ -71,27 +78,67 @@ func runTagCmd(cmd *cobra.Command, args []string) (err error) {
 		return fmt.Errorf("tag is required")
 	}
 
-	repo := remote.Repo{
-		Owner: viper.GetString("owner"),
-		Name:  viper.GetString("repo"),
+	tagRefName, err := util.QualifiedRefName(tagName, "tags")
+	if err != nil {
+		return fmt.Errorf("invalid tag reference: %s: %w", tagRefName, err)
 	}
 
+	lightweight := viper.GetBool("lightweight")
+
+	author, committer, err := buildIdentities()
+	if err != nil {
+		return err
+	}
+	// the committer, if given, is the more natural tagger identity
+	tagger := cmp.Or(committer, author)
+	if tagger != nil && lightweight {
+		log.Warn("lightweight tags carry no tagger metadata; ignoring explicit identity")
+	}
+
+	message := ""
+	if !lightweight {
+		// co-authors are resolved once, as they are common to all repositories
+		client, err := remote.NewClient(ctx, &remote.Repo{})
+		if err != nil {
+			return fmt.Errorf("NewClient(): %w", err)
+		}
+
+		if err := resolveCoAuthors(client); err != nil {
+			return err
+		}
+
+		message = util.BuildCommitMessage()
+	}
+
+	return runForRepos(cmd, func(ctx context.Context, repo remote.Repo) CommandOutput {
+		return tagRepo(ctx, repo, tagName, tagRefName, message, tagger)
+	})
+}
+
+func tagRepo(ctx context.Context, repo remote.Repo, tagName, tagRefName, message string, tagger *remote.Identity) *TagOutput {
 	lightweight := viper.GetBool("lightweight")
 	force := viper.GetBool("force")
 	update := false
 
+	output := &TagOutput{
+		Tag: tagName,
+	}
+
 	client, err := remote.NewClient(ctx, &repo)
 	if err != nil {
-		return fmt.Errorf("NewClient(%s): %w", repo, err)
+		output.SetError(fmt.Errorf("NewClient(%s): %w", repo, err))
+		return output
 	}
 
 	repoInfo, err := client.GetRepositoryInfo("")
 	if err != nil {
-		return fmt.Errorf("GetRepositoryInfo(%s): %w", repo, err)
+		output.SetError(fmt.Errorf("GetRepositoryInfo(%s): %w", repo, err))
+		return output
 	}
 
 	if repoInfo.IsEmpty {
-		return errors.New("cannot tag empty repository")
+		output.SetError(errors.New("cannot tag empty repository"))
+		return output
 	}
 
 	commitish := viper.GetString("commitish")
@@ -99,27 +146,21 @@ func runTagCmd(cmd *cobra.Command, args []string) (err error) {
 	if commitish != "" {
 		targetSha, err = client.ResolveCommitish(commitish)
 		if err != nil {
-			return fmt.Errorf("ResolveCommitish(%s, %s): %w", repo, commitish, err)
+			output.SetError(fmt.Errorf("ResolveCommitish(%s, %s): %w", repo, commitish, err))
+			return output
 		}
 		if targetSha == "" {
-			return fmt.Errorf("commitish %q not found", commitish)
+			output.SetError(fmt.Errorf("commitish %q not found", commitish))
+			return output
 		}
 	} else {
 		commitish = repoInfo.DefaultBranch.Name
 		targetSha = string(repoInfo.DefaultBranch.Commit)
 	}
 
-	output := &TagOutput{
-		Tag:       tagName,
-		Commitish: commitish,
-		SHA:       targetSha,
-		URL:       client.GetCommitURL(targetSha),
-	}
-
-	tagRefName, err := util.QualifiedRefName(tagName, "tags")
-	if err != nil {
-		return fmt.Errorf("invalid tag reference: %s: %w", tagRefName, err)
-	}
+	output.Commitish = commitish
+	output.SHA = targetSha
+	output.URL = client.GetCommitURL(targetSha)
 
 	log.Infof("checking tag reference: %s", tagRefName)
 
@@ -132,7 +173,8 @@ func runTagCmd(cmd *cobra.Command, args []string) (err error) {
 	if err != nil {
 		// tag does not exist, or other error
 		if !errors.Is(err, remote.ErrNoMatchingObject) {
-			return fmt.Errorf("GetTagObj(%s): %w", tagRefName, err)
+			output.SetError(fmt.Errorf("GetTagObj(%s): %w", tagRefName, err))
+			return output
 		}
 		log.Debug("tag does not exist")
 		// fallthrough to create non-existent tag
@@ -142,7 +184,7 @@ func runTagCmd(cmd *cobra.Command, args []string) (err error) {
 		if targetSha == tagObj.Commit.SHA && lightweight == tagObj.Lightweight {
 			// matching tag already exists
 			log.Infof("tag exists: idempotent")
-			return cmdOutput(cmd, output)
+			return output
 		} else if !force {
 			log.Infof("tag exists: wrong type without force")
 			// tag exists but points to a different commit
@@ -152,7 +194,7 @@ func runTagCmd(cmd *cobra.Command, args []string) (err error) {
 				err = fmt.Errorf("annotated tag already exists, targeting %s", tagObj.Commit.SHA)
 			}
 			output.SetError(err)
-			return cmdOutput(cmd, output)
+			return output
 		} else {
 			log.Infof("tag exists: forcing update")
 			update = true
@@ -160,12 +202,11 @@ func runTagCmd(cmd *cobra.Command, args []string) (err error) {
 	}
 
 	if !lightweight {
-		message := util.BuildCommitMessage()
 		log.Debugf("creating tag object: %s", tagName)
-		tag, err := client.CreateTag(tagName, message, targetSha)
+		tag, err := client.CreateTag(tagName, message, targetSha, tagger)
 		if err != nil {
 			output.SetError(fmt.Errorf("creating tag object: %w", err))
-			return cmdOutput(cmd, output)
+			return output
 		}
 
 		log.Debugf("created tag object: %+v", tag)
@@ -193,5 +234,5 @@ func runTagCmd(cmd *cobra.Command, args []string) (err error) {
 		output.SetError(err)
 	}
 
-	return cmdOutput(cmd, output)
+	return output
 }
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -49,7 +50,10 @@ func cmdUpdateRef() *cobra.Command {
 		Long: `Update target refs to match source commitish.
 Source commitish may also be passed via the GHUP_SOURCE environment variable,
 and target refs via GHUP_TARGETS (space-delimited).`,
-		RunE: runUpdateRefCmd,
+		RunE: withNotifyHooks(runUpdateRefCmd),
+		Annotations: map[string]string{
+			fanOutAnnotation: "true",
+		},
 	}
 
 	flags := cmd.Flags()
@@ -75,6 +79,7 @@ and target refs via GHUP_TARGETS (space-delimited).`,
 	addForceFlag(flags)
 
 	flags.Bool("immutable", false, "skip update if target ref exists and does not match source ref")
+	addNotifyFlags(flags)
 
 	flags.SetNormalizeFunc(normalizeFlags)
 	flags.SortFlags = false
@@ -83,17 +88,11 @@ and target refs via GHUP_TARGETS (space-delimited).`,
 }
 
 func runUpdateRefCmd(cmd *cobra.Command, args []string) (err error) {
-	ctx := cmd.Context()
-
 	commitish := viper.GetString("source")
 	if commitish == "" {
 		return errors.New("no source ref specified")
 	}
 
-	repo := remote.Repo{
-		Owner: viper.GetString("owner"),
-		Name:  viper.GetString("repo"),
-	}
 	force := viper.GetBool("force")
 	immutable := viper.GetBool("immutable")
 
@@ -101,32 +100,6 @@ func runUpdateRefCmd(cmd *cobra.Command, args []string) (err error) {
 		return errors.New("cannot use --force and --immutable together")
 	}
 
-	client, err := remote.NewClient(ctx, &repo)
-	if err != nil {
-		return fmt.Errorf("NewClient(%s): %w", repo, err)
-	}
-
-	output := &UpdateRefOutput{
-		Repository: repo.String(),
-		Source: source{
-			Commitish: commitish,
-		},
-	}
-
-	commitSha, err := client.ResolveCommitish(commitish)
-	if err != nil {
-		err = fmt.Errorf("resolving commitish: %w", err)
-		output.SetError(err)
-		output.Source.Error = err.Error()
-		return cmdOutput(cmd, output)
-	}
-	if commitSha == "" {
-		err = fmt.Errorf("source commitish does not exist")
-		output.SetError(err)
-		output.Source.Error = err.Error()
-		return cmdOutput(cmd, output)
-	}
-
 	var targetRefNames []string
 	if len(args) > 0 {
 		targetRefNames = args
@@ -149,6 +122,39 @@ func runUpdateRefCmd(cmd *cobra.Command, args []string) (err error) {
 		targetRefNames[i] = targetRefName
 	}
 
+	return runForRepos(cmd, func(ctx context.Context, repo remote.Repo) CommandOutput {
+		return updateRefRepo(ctx, repo, commitish, targetRefNames, force, immutable)
+	})
+}
+
+func updateRefRepo(ctx context.Context, repo remote.Repo, commitish string, targetRefNames []string, force, immutable bool) *UpdateRefOutput {
+	output := &UpdateRefOutput{
+		Repository: repo.String(),
+		Source: source{
+			Commitish: commitish,
+		},
+	}
+
+	client, err := remote.NewClient(ctx, &repo)
+	if err != nil {
+		output.SetError(fmt.Errorf("NewClient(%s): %w", repo, err))
+		return output
+	}
+
+	commitSha, err := client.ResolveCommitish(commitish)
+	if err != nil {
+		err = fmt.Errorf("resolving commitish: %w", err)
+		output.SetError(err)
+		output.Source.Error = err.Error()
+		return output
+	}
+	if commitSha == "" {
+		err = fmt.Errorf("source commitish does not exist")
+		output.SetError(err)
+		output.Source.Error = err.Error()
+		return output
+	}
+
 	output.Source.SHA = commitSha
 	output.Target = make([]targetRef, 0, len(targetRefNames))
 
@@ -195,5 +201,5 @@ func runUpdateRefCmd(cmd *cobra.Command, args []string) (err error) {
 		output.SetError(fmt.Errorf("updating refs: %w", errors.Join(updateRefErrors...)))
 	}
 
-	return cmdOutput(cmd, output)
+	return output
 }
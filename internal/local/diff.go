@@ -0,0 +1,247 @@
+package local
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+const (
+	ChangeAdded    = "added"
+	ChangeModified = "modified"
+	ChangeDeleted  = "deleted"
+	// ChangeUnchanged marks an update identical to the remote content
+	ChangeUnchanged = "unchanged"
+	// ChangeSkipped marks a deletion of a file absent from the remote
+	ChangeSkipped = "skipped"
+
+	// DiffMaxBytes is the size above which a summary is produced instead of a diff
+	DiffMaxBytes = 1 << 20
+	// DiffContext is the number of unchanged lines around each hunk
+	DiffContext = 3
+
+	// binarySniffLen is the prefix length checked for NUL bytes, as git does
+	binarySniffLen = 8000
+)
+
+// FileChange reports the outcome of a single file change, with blob SHAs empty where absent
+type FileChange struct {
+	Path   string `json:"path" yaml:"path"`
+	Action string `json:"action" yaml:"action"`
+	OldSHA string `json:"old_sha,omitempty" yaml:"old_sha,omitempty"`
+	NewSHA string `json:"new_sha,omitempty" yaml:"new_sha,omitempty"`
+	Size   int    `json:"size" yaml:"size"`
+}
+
+// PlanChanges classifies updates and deletions against the remote blob SHAs reported by remoteSHA,
+// which is empty for absent files, returning the outcome for each path, sorted by path.
+// Unless forced, updates identical to the remote are unchanged and deletions of absent files are
+// skipped; root commits hold no deletions, as they start from an empty tree.
+func PlanChanges(pathContent PathContent, deletionSet DeletionSet, remoteSHA func(path string) string, force, root bool) []FileChange {
+	files := make([]FileChange, 0, len(pathContent)+len(deletionSet))
+
+	for path, content := range pathContent {
+		localSHA := plumbing.ComputeHash(plumbing.BlobObject, content).String()
+		file := FileChange{Path: path, OldSHA: remoteSHA(path), NewSHA: localSHA, Size: len(content)}
+		switch {
+		case file.OldSHA == localSHA && !force:
+			file.Action = ChangeUnchanged
+			log.Debugf("%q (%s) on target branch: skipping addition", path, file.OldSHA)
+		case file.OldSHA == "":
+			file.Action = ChangeAdded
+			log.Debugf("%q queued for addition", path)
+		default:
+			file.Action = ChangeModified
+			log.Debugf("%q queued for addition", path)
+		}
+		files = append(files, file)
+	}
+
+	for path := range deletionSet {
+		file := FileChange{Path: path, OldSHA: remoteSHA(path)}
+		if (file.OldSHA != "" || force) && !root {
+			file.Action = ChangeDeleted
+			log.Debugf("%q queued for deletion", path)
+		} else {
+			file.Action = ChangeSkipped
+			log.Debugf("%q absent on target branch: skipping deletion", path)
+		}
+		files = append(files, file)
+	}
+
+	slices.SortFunc(files, func(a, b FileChange) int {
+		return cmp.Compare(a.Path, b.Path)
+	})
+
+	return files
+}
+
+// FileDiff describes the change to a single file, as a unified diff or a summary
+type FileDiff struct {
+	Path    string `json:"path" yaml:"path"`
+	Action  string `json:"action" yaml:"action"`
+	Diff    string `json:"diff,omitempty" yaml:"diff,omitempty"`
+	Summary string `json:"summary,omitempty" yaml:"summary,omitempty"`
+}
+
+// String returns the diff, or a summary line for binary and large files
+func (d FileDiff) String() string {
+	if d.Diff != "" {
+		return d.Diff
+	}
+	return fmt.Sprintf("%s %s: %s\n", d.Action, d.Path, d.Summary)
+}
+
+// IsBinary reports whether content looks binary, i.e. has a NUL byte near the start
+func IsBinary(content []byte) bool {
+	return bytes.IndexByte(content[:min(len(content), binarySniffLen)], 0) >= 0
+}
+
+// DiffContent compares old and new content of path; nil content denotes absence.
+// oldBinary flags old content known to be binary without its content being available.
+func DiffContent(path string, old, new []byte, oldBinary bool) FileDiff {
+	fileDiff := FileDiff{Path: path}
+
+	switch {
+	case old == nil && !oldBinary:
+		fileDiff.Action = ChangeAdded
+	case new == nil:
+		fileDiff.Action = ChangeDeleted
+	default:
+		fileDiff.Action = ChangeModified
+	}
+
+	switch {
+	case oldBinary || IsBinary(old) || IsBinary(new):
+		fileDiff.Summary = "binary file differs"
+		if new != nil {
+			fileDiff.Summary = fmt.Sprintf("binary file (%d bytes)", len(new))
+		}
+	case len(old) > DiffMaxBytes || len(new) > DiffMaxBytes:
+		fileDiff.Summary = fmt.Sprintf("large file (%d -> %d bytes): diff omitted", len(old), len(new))
+	default:
+		fileDiff.Diff = UnifiedDiff(path, old, new, DiffContext)
+		if fileDiff.Diff == "" && fileDiff.Action == ChangeModified {
+			fileDiff.Summary = "no content changes"
+		} else if fileDiff.Diff == "" {
+			fileDiff.Summary = "empty file"
+		}
+	}
+
+	return fileDiff
+}
+
+type diffLine struct {
+	kind      byte // ' ', '-' or '+'
+	text      string
+	noNewline bool
+}
+
+// UnifiedDiff returns a unified diff between old and new content, with nil denoting absence
+func UnifiedDiff(path string, old, new []byte, context int) string {
+	lines := make([]diffLine, 0)
+	for _, d := range diff.Do(string(old), string(new)) {
+		var kind byte
+		switch d.Type {
+		case diffmatchpatch.DiffEqual:
+			kind = ' '
+		case diffmatchpatch.DiffDelete:
+			kind = '-'
+		case diffmatchpatch.DiffInsert:
+			kind = '+'
+		}
+		for line := range strings.SplitAfterSeq(d.Text, "\n") {
+			if line == "" {
+				continue
+			}
+			lines = append(lines, diffLine{
+				kind:      kind,
+				text:      strings.TrimSuffix(line, "\n"),
+				noNewline: !strings.HasSuffix(line, "\n"),
+			})
+		}
+	}
+
+	var sb strings.Builder
+
+	oldName, newName := "a/"+path, "b/"+path
+	if old == nil {
+		oldName = "/dev/null"
+	}
+	if new == nil {
+		newName = "/dev/null"
+	}
+
+	for i := 0; i < len(lines); {
+		// skip to the next change
+		for i < len(lines) && lines[i].kind == ' ' {
+			i++
+		}
+		if i == len(lines) {
+			break
+		}
+
+		// extend the hunk until changes are separated by more than twice the context
+		last := i
+		for j := i; j < len(lines) && j-last <= 2*context; j++ {
+			if lines[j].kind != ' ' {
+				last = j
+			}
+		}
+
+		start := max(0, i-context)
+		end := min(len(lines), last+context+1)
+
+		if sb.Len() == 0 {
+			fmt.Fprintf(&sb, "--- %s\n+++ %s\n", oldName, newName)
+		}
+
+		oldBefore, newBefore := countLines(lines[:start])
+		oldCount, newCount := countLines(lines[start:end])
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(oldBefore, oldCount), hunkRange(newBefore, newCount))
+
+		for _, line := range lines[start:end] {
+			sb.WriteByte(line.kind)
+			sb.WriteString(line.text)
+			sb.WriteByte('\n')
+			if line.noNewline {
+				sb.WriteString("\\ No newline at end of file\n")
+			}
+		}
+
+		i = end
+	}
+
+	return sb.String()
+}
+
+// countLines counts the lines present in the old and new versions
+func countLines(lines []diffLine) (oldCount, newCount int) {
+	for _, line := range lines {
+		if line.kind != '+' {
+			oldCount++
+		}
+		if line.kind != '-' {
+			newCount++
+		}
+	}
+	return oldCount, newCount
+}
+
+// hunkRange formats a unified diff hunk range, following GNU diff conventions
+func hunkRange(before, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", before)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", before+1)
+	}
+	return fmt.Sprintf("%d,%d", before+1, count)
+}
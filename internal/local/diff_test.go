@@ -0,0 +1,248 @@
+package local
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		old      []byte
+		new      []byte
+		expected string
+	}{
+		{
+			name:     "Identical",
+			old:      []byte("a\nb\n"),
+			new:      []byte("a\nb\n"),
+			expected: "",
+		},
+		{
+			name: "Added file",
+			old:  nil,
+			new:  []byte("a\nb\n"),
+			expected: `--- /dev/null
++++ b/file.txt
+@@ -0,0 +1,2 @@
++a
++b
+`,
+		},
+		{
+			name: "Deleted file",
+			old:  []byte("a\n"),
+			new:  nil,
+			expected: `--- a/file.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-a
+`,
+		},
+		{
+			name: "Modified line with context",
+			old:  []byte("1\n2\n3\n4\n5\n6\n7\n8\n9\n"),
+			new:  []byte("1\n2\n3\n4\nfive\n6\n7\n8\n9\n"),
+			expected: `--- a/file.txt
++++ b/file.txt
+@@ -2,7 +2,7 @@
+ 2
+ 3
+ 4
+-5
++five
+ 6
+ 7
+ 8
+`,
+		},
+		{
+			name: "Separate hunks",
+			old:  []byte("1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"),
+			new:  []byte("one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ntwelve\n"),
+			expected: `--- a/file.txt
++++ b/file.txt
+@@ -1,4 +1,4 @@
+-1
++one
+ 2
+ 3
+ 4
+@@ -9,4 +9,4 @@
+ 9
+ 10
+ 11
+-12
++twelve
+`,
+		},
+		{
+			name: "Missing trailing newline",
+			old:  []byte("a\nb"),
+			new:  []byte("a\nb\n"),
+			expected: `--- a/file.txt
++++ b/file.txt
+@@ -1,2 +1,2 @@
+ a
+-b
+\ No newline at end of file
++b
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := UnifiedDiff("file.txt", tt.old, tt.new, DiffContext)
+			if result != tt.expected {
+				t.Errorf("UnifiedDiff() =\n%s\nexpected:\n%s", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDiffContent(t *testing.T) {
+	tests := []struct {
+		name           string
+		old            []byte
+		new            []byte
+		oldBinary      bool
+		expectedAction string
+		expectDiff     bool
+		summaryPrefix  string
+	}{
+		{
+			name:           "Added text",
+			new:            []byte("hello\n"),
+			expectedAction: ChangeAdded,
+			expectDiff:     true,
+		},
+		{
+			name:           "Deleted text",
+			old:            []byte("hello\n"),
+			expectedAction: ChangeDeleted,
+			expectDiff:     true,
+		},
+		{
+			name:           "Binary new content",
+			old:            []byte("hello\n"),
+			new:            []byte{0x89, 'P', 'N', 'G', 0x00},
+			expectedAction: ChangeModified,
+			summaryPrefix:  "binary file",
+		},
+		{
+			name:           "Binary old content",
+			new:            []byte("hello\n"),
+			oldBinary:      true,
+			expectedAction: ChangeModified,
+			summaryPrefix:  "binary file",
+		},
+		{
+			name:           "Large file",
+			old:            []byte("small\n"),
+			new:            bytes.Repeat([]byte("x\n"), DiffMaxBytes),
+			expectedAction: ChangeModified,
+			summaryPrefix:  "large file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := DiffContent("file", tt.old, tt.new, tt.oldBinary)
+			if result.Action != tt.expectedAction {
+				t.Errorf("DiffContent().Action = %q; expected %q", result.Action, tt.expectedAction)
+			}
+			if tt.expectDiff != (result.Diff != "") {
+				t.Errorf("DiffContent().Diff = %q; expected diff: %v", result.Diff, tt.expectDiff)
+			}
+			if !strings.HasPrefix(result.Summary, tt.summaryPrefix) {
+				t.Errorf("DiffContent().Summary = %q; expected prefix %q", result.Summary, tt.summaryPrefix)
+			}
+		})
+	}
+}
+
+func TestPlanChanges(t *testing.T) {
+	blobSHA := func(content string) string {
+		return plumbing.ComputeHash(plumbing.BlobObject, []byte(content)).String()
+	}
+
+	remote := map[string]string{
+		"same.txt":    blobSHA("same\n"),
+		"changed.txt": blobSHA("old\n"),
+		"gone.txt":    blobSHA("gone\n"),
+	}
+	remoteSHA := func(path string) string {
+		return remote[path]
+	}
+
+	pathContent := PathContent{
+		"same.txt":    []byte("same\n"),
+		"changed.txt": []byte("new\n"),
+		"new.txt":     []byte("new\n"),
+	}
+	deletionSet := DeletionSet{
+		"gone.txt":   {},
+		"absent.txt": {},
+	}
+
+	tests := []struct {
+		name            string
+		force           bool
+		root            bool
+		expectedActions []string // in path order: absent, changed, gone, new, same
+	}{
+		{
+			name:            "Idempotent",
+			expectedActions: []string{ChangeSkipped, ChangeModified, ChangeDeleted, ChangeAdded, ChangeUnchanged},
+		},
+		{
+			name:            "Forced",
+			force:           true,
+			expectedActions: []string{ChangeDeleted, ChangeModified, ChangeDeleted, ChangeAdded, ChangeModified},
+		},
+		{
+			name:            "Root commit",
+			root:            true,
+			expectedActions: []string{ChangeSkipped, ChangeModified, ChangeSkipped, ChangeAdded, ChangeUnchanged},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := PlanChanges(pathContent, deletionSet, remoteSHA, tt.force, tt.root)
+
+			paths := make([]string, 0, len(files))
+			actions := make([]string, 0, len(files))
+			for _, file := range files {
+				paths = append(paths, file.Path)
+				actions = append(actions, file.Action)
+			}
+
+			expectedPaths := []string{"absent.txt", "changed.txt", "gone.txt", "new.txt", "same.txt"}
+			if !slices.Equal(paths, expectedPaths) {
+				t.Fatalf("PlanChanges() paths = %v; expected %v", paths, expectedPaths)
+			}
+			if !slices.Equal(actions, tt.expectedActions) {
+				t.Errorf("PlanChanges() actions = %v; expected %v", actions, tt.expectedActions)
+			}
+
+			for _, file := range files {
+				if content, ok := pathContent[file.Path]; ok {
+					if file.NewSHA != blobSHA(string(content)) || file.Size != len(content) {
+						t.Errorf("PlanChanges() %q = %+v; expected new SHA %s and size %d", file.Path, file, blobSHA(string(content)), len(content))
+					}
+				} else if file.NewSHA != "" {
+					t.Errorf("PlanChanges() %q new SHA = %q; expected none for deletion", file.Path, file.NewSHA)
+				}
+				if file.OldSHA != remote[file.Path] {
+					t.Errorf("PlanChanges() %q old SHA = %q; expected %q", file.Path, file.OldSHA, remote[file.Path])
+				}
+			}
+		})
+	}
+}
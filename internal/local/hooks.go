@@ -0,0 +1,147 @@
+package local
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+
+	"github.com/nexthink-oss/ghup/internal/util"
+)
+
+const (
+	HookModeTransform = "transform"
+	HookModeValidate  = "validate"
+
+	defaultHookTimeout = time.Minute
+)
+
+var (
+	ErrHookNoCommand   = errors.New("hook has no command")
+	ErrHookNoGlob      = errors.New("hook has no glob")
+	ErrHookInvalidGlob = errors.New("invalid hook glob")
+	ErrHookInvalidMode = errors.New("invalid hook mode")
+)
+
+// PreCommitHook is a local command run against the content of matching paths before committing.
+// The content is passed on stdin; in transform mode, stdout replaces the content.
+// A non-zero exit status fails the commit in either mode.
+type PreCommitHook struct {
+	Name    string        `mapstructure:"name"`
+	Glob    string        `mapstructure:"glob"`
+	Command []string      `mapstructure:"command"`
+	Mode    string        `mapstructure:"mode"`
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// HookResult reports the outcome of running a pre-commit hook against a path
+type HookResult struct {
+	Path    string `json:"path" yaml:"path"`
+	Hook    string `json:"hook" yaml:"hook"`
+	Changed bool   `json:"changed" yaml:"changed"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// Validate checks the hook definition and applies defaults
+func (h *PreCommitHook) Validate() error {
+	errs := make([]error, 0)
+
+	if len(h.Command) == 0 || h.Command[0] == "" {
+		errs = append(errs, ErrHookNoCommand)
+	} else {
+		h.Name = cmp.Or(h.Name, h.Command[0])
+	}
+
+	if h.Glob == "" {
+		errs = append(errs, ErrHookNoGlob)
+	} else if err := util.ValidateGlob(h.Glob); err != nil {
+		errs = append(errs, fmt.Errorf("%w: %w", ErrHookInvalidGlob, err))
+	}
+
+	switch h.Mode {
+	case "":
+		h.Mode = HookModeTransform
+	case HookModeTransform, HookModeValidate:
+	default:
+		errs = append(errs, fmt.Errorf("%w: %q", ErrHookInvalidMode, h.Mode))
+	}
+
+	h.Timeout = cmp.Or(h.Timeout, defaultHookTimeout)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("hook %q: %w", cmp.Or(h.Name, h.Glob), errors.Join(errs...))
+	}
+
+	return nil
+}
+
+// Run executes the hook against the content of path, returning the resulting content
+func (h *PreCommitHook) Run(ctx context.Context, path string, content []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, h.Timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, h.Command[0], h.Command[1:]...)
+	cmd.WaitDelay = time.Second
+	cmd.Env = append(os.Environ(), fmt.Sprintf("GHUP_HOOK_PATH=%s", path))
+	cmd.Stdin = bytes.NewReader(content)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+
+	if h.Mode == HookModeValidate {
+		return content, nil
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// ApplyHooks runs each matching hook against every path, in order, updating content in place.
+// All paths are processed so that every failure is reported, but any failure results in an error.
+func (p PathContent) ApplyHooks(ctx context.Context, hooks []PreCommitHook) (results []HookResult, err error) {
+	errs := make([]error, 0)
+
+	for _, path := range p.Keys() {
+		for _, hook := range hooks {
+			if !util.MatchGlob(hook.Glob, path) {
+				continue
+			}
+
+			log.Debugf("running %s hook %q on %q", hook.Mode, hook.Name, path)
+
+			result := HookResult{
+				Path: path,
+				Hook: hook.Name,
+			}
+
+			content, err := hook.Run(ctx, path, p[path])
+			if err != nil {
+				err = fmt.Errorf("hook %q on %q: %w", hook.Name, path, err)
+				errs = append(errs, err)
+				result.Error = err.Error()
+				results = append(results, result)
+				break // later hooks would see stale content
+			}
+
+			result.Changed = !bytes.Equal(content, p[path])
+			p[path] = content
+			results = append(results, result)
+		}
+	}
+
+	return results, errors.Join(errs...)
+}
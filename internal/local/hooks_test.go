@@ -0,0 +1,145 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPreCommitHookValidate(t *testing.T) {
+	tests := []struct {
+		name         string
+		hook         PreCommitHook
+		wantErr      error
+		expectedName string
+		expectedMode string
+	}{
+		{
+			name:         "Defaults",
+			hook:         PreCommitHook{Glob: "*.go", Command: []string{"gofmt"}},
+			expectedName: "gofmt",
+			expectedMode: HookModeTransform,
+		},
+		{
+			name:         "Explicit validate mode",
+			hook:         PreCommitHook{Name: "lint", Glob: "*.json", Command: []string{"jq", "."}, Mode: HookModeValidate},
+			expectedName: "lint",
+			expectedMode: HookModeValidate,
+		},
+		{
+			name:    "Missing command",
+			hook:    PreCommitHook{Glob: "*.go"},
+			wantErr: ErrHookNoCommand,
+		},
+		{
+			name:    "Missing glob",
+			hook:    PreCommitHook{Command: []string{"gofmt"}},
+			wantErr: ErrHookNoGlob,
+		},
+		{
+			name:    "Unterminated character class in glob",
+			hook:    PreCommitHook{Glob: "config/[a-z.yaml", Command: []string{"yamlfmt"}},
+			wantErr: ErrHookInvalidGlob,
+		},
+		{
+			name:    "Invalid mode",
+			hook:    PreCommitHook{Glob: "*.go", Command: []string{"gofmt"}, Mode: "rewrite"},
+			wantErr: ErrHookInvalidMode,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.hook.Validate()
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Validate() error = %v; expected %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Validate() unexpected error: %v", err)
+			}
+			if tt.hook.Name != tt.expectedName {
+				t.Errorf("Validate() Name = %q; expected %q", tt.hook.Name, tt.expectedName)
+			}
+			if tt.hook.Mode != tt.expectedMode {
+				t.Errorf("Validate() Mode = %q; expected %q", tt.hook.Mode, tt.expectedMode)
+			}
+			if tt.hook.Timeout != defaultHookTimeout {
+				t.Errorf("Validate() Timeout = %v; expected %v", tt.hook.Timeout, defaultHookTimeout)
+			}
+		})
+	}
+}
+
+func TestPathContentApplyHooks(t *testing.T) {
+	upper := PreCommitHook{Name: "upper", Glob: "*.txt", Command: []string{"tr", "a-z", "A-Z"}}
+	failing := PreCommitHook{Name: "fail", Glob: "bad/*", Command: []string{"sh", "-c", "echo broken >&2; exit 1"}, Mode: HookModeValidate}
+	validate := PreCommitHook{Name: "check", Glob: "**/*.txt", Command: []string{"grep", "-q", "."}, Mode: HookModeValidate}
+	slow := PreCommitHook{Name: "slow", Glob: "*", Command: []string{"sleep", "5"}, Timeout: 10 * time.Millisecond}
+
+	for _, hook := range []*PreCommitHook{&upper, &failing, &validate} {
+		if err := hook.Validate(); err != nil {
+			t.Fatalf("Validate(): %v", err)
+		}
+	}
+
+	tests := []struct {
+		name            string
+		content         PathContent
+		hooks           []PreCommitHook
+		expectedContent PathContent
+		expectedResults int
+		wantErr         bool
+	}{
+		{
+			name:            "Transform matching path",
+			content:         PathContent{"a.txt": []byte("hello"), "b.md": []byte("world")},
+			hooks:           []PreCommitHook{upper},
+			expectedContent: PathContent{"a.txt": []byte("HELLO"), "b.md": []byte("world")},
+			expectedResults: 1,
+		},
+		{
+			name:            "Transform then validate",
+			content:         PathContent{"dir/a.txt": []byte("hello")},
+			hooks:           []PreCommitHook{upper, validate},
+			expectedContent: PathContent{"dir/a.txt": []byte("HELLO")},
+			expectedResults: 2,
+		},
+		{
+			name:            "Failing hook",
+			content:         PathContent{"bad/file": []byte("content")},
+			hooks:           []PreCommitHook{failing},
+			expectedContent: PathContent{"bad/file": []byte("content")},
+			expectedResults: 1,
+			wantErr:         true,
+		},
+		{
+			name:            "Timeout",
+			content:         PathContent{"file": []byte("content")},
+			hooks:           []PreCommitHook{slow},
+			expectedContent: PathContent{"file": []byte("content")},
+			expectedResults: 1,
+			wantErr:         true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := tt.content.ApplyHooks(context.Background(), tt.hooks)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ApplyHooks() error = %v; wantErr %v", err, tt.wantErr)
+			}
+			if len(results) != tt.expectedResults {
+				t.Errorf("ApplyHooks() returned %d results; expected %d: %+v", len(results), tt.expectedResults, results)
+			}
+			for path, expected := range tt.expectedContent {
+				if string(tt.content[path]) != string(expected) {
+					t.Errorf("content[%q] = %q; expected %q", path, tt.content[path], expected)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package local
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+)
+
+var (
+	ErrMoveWithBranch = errors.New("move spec cannot reference another branch")
+)
+
+// Manifest declaratively describes a set of content changes, as an alternative
+// to long lists of command-line flags. File specs use the same syntax as the
+// corresponding flags, and are validated with ParseUpdateSpec and ParseCopySpec.
+type Manifest struct {
+	Branch      string               `yaml:"branch"`
+	BaseBranch  string               `yaml:"base-branch"`
+	Message     string               `yaml:"message"`
+	Updates     []string             `yaml:"updates"`
+	Deletes     []string             `yaml:"deletes"`
+	Copies      []string             `yaml:"copies"`
+	Moves       []string             `yaml:"moves"`
+	PullRequest *ManifestPullRequest `yaml:"pull-request"`
+}
+
+// ManifestPullRequest describes the pull request settings of a Manifest
+type ManifestPullRequest struct {
+	Title     string `yaml:"title"`
+	Body      string `yaml:"body"`
+	BodyFile  string `yaml:"body-file"`
+	Template  *bool  `yaml:"template"`
+	Draft     *bool  `yaml:"draft"`
+	AutoMerge string `yaml:"auto-merge"`
+	Update    *bool  `yaml:"update"`
+
+	Reviewers    []string `yaml:"reviewers"`
+	Assignees    []string `yaml:"assignees"`
+	Labels       []string `yaml:"labels"`
+	Milestone    string   `yaml:"milestone"`
+	CreateLabels *bool    `yaml:"create-labels"`
+
+	Repo string `yaml:"repo"`
+}
+
+// ManifestError reports a manifest validation error with its source position
+type ManifestError struct {
+	File string
+	Line int
+	Path string
+	Err  error
+}
+
+func (e *ManifestError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s: %v", e.File, e.Line, e.Path, e.Err)
+	}
+	return fmt.Sprintf("%s: %s: %v", e.File, e.Path, e.Err)
+}
+
+func (e *ManifestError) Unwrap() error {
+	return e.Err
+}
+
+// LoadManifest reads and validates a YAML or JSON manifest file.
+// All validation errors are reported together, each with its line number.
+func LoadManifest(path, separator string, autoMergeChoices []string) (*Manifest, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ReadFile(%s): %w", path, err)
+	}
+
+	manifest := &Manifest{}
+	if err := yaml.UnmarshalWithOptions(content, manifest, yaml.DisallowUnknownField()); err != nil {
+		return nil, fmt.Errorf("%s: %s", path, yaml.FormatError(err, false, true))
+	}
+
+	file, err := parser.ParseBytes(content, 0)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	errs := make([]error, 0)
+	addError := func(yamlPath string, err error) {
+		errs = append(errs, &ManifestError{
+			File: path,
+			Line: nodeLine(file, yamlPath),
+			Path: yamlPath,
+			Err:  err,
+		})
+	}
+
+	for i, spec := range manifest.Updates {
+		if _, _, err := ParseUpdateSpec(spec, separator); err != nil {
+			addError(fmt.Sprintf("$.updates[%d]", i), err)
+		}
+	}
+
+	for i, target := range manifest.Deletes {
+		if target == "" {
+			addError(fmt.Sprintf("$.deletes[%d]", i), ErrEmptyTargetSpec)
+		}
+	}
+
+	for i, spec := range manifest.Copies {
+		if _, _, _, err := ParseCopySpec(spec, separator); err != nil {
+			addError(fmt.Sprintf("$.copies[%d]", i), err)
+		}
+	}
+
+	for i, spec := range manifest.Moves {
+		if branch, _, _, err := ParseCopySpec(spec, separator); err != nil {
+			addError(fmt.Sprintf("$.moves[%d]", i), err)
+		} else if branch != "" {
+			addError(fmt.Sprintf("$.moves[%d]", i), ErrMoveWithBranch)
+		}
+	}
+
+	if pr := manifest.PullRequest; pr != nil {
+		if pr.AutoMerge != "" && !slices.Contains(autoMergeChoices, pr.AutoMerge) {
+			addError("$.pull-request.auto-merge", fmt.Errorf("invalid auto-merge method %q (choices: %v)", pr.AutoMerge, autoMergeChoices))
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid manifest: %w", errors.Join(errs...))
+	}
+
+	manifest.resolvePaths(filepath.Dir(path), separator)
+
+	return manifest, nil
+}
+
+// nodeLine returns the line number of the node at yamlPath, or 0 if not found
+func nodeLine(file *ast.File, yamlPath string) int {
+	p, err := yaml.PathString(yamlPath)
+	if err != nil {
+		return 0
+	}
+
+	node, err := p.FilterFile(file)
+	if err != nil || node == nil || node.GetToken() == nil {
+		return 0
+	}
+
+	return node.GetToken().Position.Line
+}
+
+// resolvePaths resolves relative local paths against dir, the directory of the manifest,
+// so that a manifest behaves the same regardless of the working directory
+func (m *Manifest) resolvePaths(dir, separator string) {
+	resolve := func(path string) string {
+		if path == StdinSource || filepath.IsAbs(path) {
+			return path
+		}
+		return filepath.Join(dir, path)
+	}
+
+	for i, spec := range m.Updates {
+		// specs were validated by LoadManifest
+		source, target, _ := ParseUpdateSpec(spec, separator)
+		m.Updates[i] = resolve(source) + separator + target
+	}
+
+	if m.PullRequest != nil && m.PullRequest.BodyFile != "" {
+		m.PullRequest.BodyFile = resolve(m.PullRequest.BodyFile)
+	}
+}
+
+// Settings returns the settings of the manifest other than file specs, keyed by flag name
+func (m *Manifest) Settings() map[string]any {
+	settings := make(map[string]any)
+
+	if m.Branch != "" {
+		settings["branch"] = []string{m.Branch}
+	}
+	if m.BaseBranch != "" {
+		settings["base-branch"] = m.BaseBranch
+	}
+	if m.Message != "" {
+		settings["message"] = m.Message
+	}
+
+	if pr := m.PullRequest; pr != nil {
+		if pr.Title != "" {
+			settings["pr-title"] = pr.Title
+		}
+		if pr.Body != "" {
+			settings["pr-body"] = pr.Body
+		}
+		if pr.BodyFile != "" {
+			settings["pr-body-file"] = pr.BodyFile
+		}
+		if pr.Template != nil {
+			settings["pr-template"] = *pr.Template
+		}
+		if pr.Draft != nil {
+			settings["pr-draft"] = *pr.Draft
+		}
+		if pr.AutoMerge != "" {
+			settings["pr-auto-merge"] = pr.AutoMerge
+		}
+		if pr.Update != nil {
+			settings["pr-update"] = *pr.Update
+		}
+		if len(pr.Reviewers) > 0 {
+			settings["pr-reviewer"] = pr.Reviewers
+		}
+		if len(pr.Assignees) > 0 {
+			settings["pr-assignee"] = pr.Assignees
+		}
+		if len(pr.Labels) > 0 {
+			settings["pr-label"] = pr.Labels
+		}
+		if pr.Milestone != "" {
+			settings["pr-milestone"] = pr.Milestone
+		}
+		if pr.CreateLabels != nil {
+			settings["pr-create-labels"] = *pr.CreateLabels
+		}
+		if pr.Repo != "" {
+			settings["pr-repo"] = pr.Repo
+		}
+	}
+
+	return settings
+}
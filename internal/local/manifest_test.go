@@ -0,0 +1,186 @@
+package local
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var testAutoMergeChoices = []string{"off", "merge", "squash", "rebase"}
+
+func writeManifest(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("writing manifest: %v", err)
+	}
+	return path
+}
+
+func TestLoadManifest(t *testing.T) {
+	path := writeManifest(t, "changes.yaml", `branch: bot/update
+base-branch: main
+message: Update generated files
+updates:
+  - local/a.txt:remote/a.txt
+  - b.txt
+deletes:
+  - obsolete.txt
+copies:
+  - release:src.txt:dst.txt
+moves:
+  - old/path.txt:new/path.txt
+pull-request:
+  title: Update generated files
+  draft: true
+  auto-merge: squash
+  reviewers: [alice, acme/platform]
+  labels: [dependencies]
+`)
+
+	manifest, err := LoadManifest(path, ":", testAutoMergeChoices)
+	if err != nil {
+		t.Fatalf("LoadManifest() unexpected error: %v", err)
+	}
+
+	if manifest.Branch != "bot/update" || manifest.BaseBranch != "main" {
+		t.Errorf("LoadManifest() branches = %q, %q", manifest.Branch, manifest.BaseBranch)
+	}
+	if len(manifest.Updates) != 2 || len(manifest.Deletes) != 1 || len(manifest.Copies) != 1 || len(manifest.Moves) != 1 {
+		t.Errorf("LoadManifest() = %+v; unexpected spec counts", manifest)
+	}
+	dir := filepath.Dir(path)
+	expectedUpdates := []string{
+		filepath.Join(dir, "local/a.txt") + ":remote/a.txt",
+		filepath.Join(dir, "b.txt") + ":b.txt",
+	}
+	if !slices.Equal(manifest.Updates, expectedUpdates) {
+		t.Errorf("LoadManifest() Updates = %v; expected paths relative to the manifest %v", manifest.Updates, expectedUpdates)
+	}
+	if manifest.PullRequest == nil || manifest.PullRequest.Draft == nil || !*manifest.PullRequest.Draft {
+		t.Errorf("LoadManifest() PullRequest = %+v; expected draft", manifest.PullRequest)
+	}
+	if manifest.PullRequest.Update != nil {
+		t.Errorf("LoadManifest() PullRequest.Update = %v; expected unset", *manifest.PullRequest.Update)
+	}
+	if len(manifest.PullRequest.Reviewers) != 2 || len(manifest.PullRequest.Labels) != 1 {
+		t.Errorf("LoadManifest() PullRequest = %+v; expected reviewers and labels", manifest.PullRequest)
+	}
+}
+
+func TestLoadManifestJSON(t *testing.T) {
+	path := writeManifest(t, "changes.json", `{"branch": "bot/update", "updates": ["a.txt:b.txt"]}`)
+
+	manifest, err := LoadManifest(path, ":", testAutoMergeChoices)
+	if err != nil {
+		t.Fatalf("LoadManifest() unexpected error: %v", err)
+	}
+	if manifest.Branch != "bot/update" || len(manifest.Updates) != 1 {
+		t.Errorf("LoadManifest() = %+v", manifest)
+	}
+}
+
+func TestLoadManifestErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		expectedLines []string
+		expectedErr   error
+	}{
+		{
+			name: "Invalid specs",
+			content: `updates:
+  - ok.txt
+  - ":remote.txt"
+copies:
+  - a.txt:a.txt
+moves:
+  - branch:a.txt:b.txt
+pull-request:
+  auto-merge: fast-forward
+`,
+			expectedLines: []string{"changes.yaml:3:", "changes.yaml:5:", "changes.yaml:7:", "changes.yaml:9:"},
+			expectedErr:   ErrMoveWithBranch,
+		},
+		{
+			name:          "Unknown field",
+			content:       "branch: main\nupdate:\n  - a.txt\n",
+			expectedLines: []string{"unknown field \"update\""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeManifest(t, "changes.yaml", tt.content)
+			_, err := LoadManifest(path, ":", testAutoMergeChoices)
+			if err == nil {
+				t.Fatal("LoadManifest() expected error, got nil")
+			}
+			for _, expected := range tt.expectedLines {
+				if !strings.Contains(err.Error(), expected) {
+					t.Errorf("LoadManifest() error = %v; expected to contain %q", err, expected)
+				}
+			}
+			if tt.expectedErr != nil && !errors.Is(err, tt.expectedErr) {
+				t.Errorf("LoadManifest() error = %v; expected %v", err, tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestLoadManifestPaths(t *testing.T) {
+	path := writeManifest(t, "changes.yaml", `updates:
+  - /abs/a.txt:a.txt
+  - sub/b.txt:b.txt
+  - "-:stdin.txt"
+pull-request:
+  body-file: body.md
+`)
+
+	manifest, err := LoadManifest(path, ":", testAutoMergeChoices)
+	if err != nil {
+		t.Fatalf("LoadManifest() unexpected error: %v", err)
+	}
+
+	if expected := []string{"/abs/a.txt:a.txt", filepath.Join(filepath.Dir(path), "sub/b.txt") + ":b.txt", "-:stdin.txt"}; !slices.Equal(manifest.Updates, expected) {
+		t.Errorf("LoadManifest() Updates = %v; expected %v", manifest.Updates, expected)
+	}
+	if expected := filepath.Join(filepath.Dir(path), "body.md"); manifest.PullRequest.BodyFile != expected {
+		t.Errorf("LoadManifest() BodyFile = %q; expected %q", manifest.PullRequest.BodyFile, expected)
+	}
+}
+
+func TestManifestSettings(t *testing.T) {
+	manifest := &Manifest{
+		Branch:  "bot/update",
+		Message: "Update generated files",
+		Updates: []string{"a.txt"},
+		PullRequest: &ManifestPullRequest{
+			Title:  "Update generated files",
+			Draft:  new(false),
+			Labels: []string{"generated"},
+		},
+	}
+
+	settings := manifest.Settings()
+
+	expected := map[string]any{
+		"branch":   []string{"bot/update"},
+		"message":  "Update generated files",
+		"pr-title": "Update generated files",
+		"pr-draft": false,
+		"pr-label": []string{"generated"},
+	}
+	if len(settings) != len(expected) {
+		t.Errorf("Settings() = %v; expected %v", settings, expected)
+	}
+	for key, value := range expected {
+		if fmt.Sprint(settings[key]) != fmt.Sprint(value) {
+			t.Errorf("Settings()[%q] = %v; expected %v", key, settings[key], value)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package local
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/apex/log"
+
+	"github.com/nexthink-oss/ghup/internal/util"
+)
+
+const (
+	// StdinSource is the update-spec source and tar path denoting standard input
+	StdinSource = "-"
+
+	// whiteoutPrefix marks tar entries denoting deletions, per the OCI image layer convention
+	whiteoutPrefix = ".wh."
+	// whiteoutOpaque marks a directory as opaque in OCI layers; ghup does not support it
+	whiteoutOpaque = whiteoutPrefix + whiteoutPrefix + ".opq"
+)
+
+var (
+	ErrStdinTarget  = errors.New("stdin source requires a remote path")
+	ErrEmptyCommand = errors.New("empty command")
+)
+
+// ParseExecSpec parses an exec specification into a command line and target path.
+// The last separator splits the command from the target, so commands may contain the separator.
+func ParseExecSpec(spec, separator string) (command, target string, err error) {
+	i := strings.LastIndex(spec, separator)
+	if i < 0 {
+		return "", "", fmt.Errorf("exec-spec %q: %w", spec, ErrInvalidSpec)
+	}
+
+	command = strings.TrimSpace(spec[:i])
+	target = spec[i+len(separator):]
+
+	errs := make([]error, 0)
+	if command == "" {
+		errs = append(errs, ErrEmptyCommand)
+	}
+	if target == "" {
+		errs = append(errs, ErrEmptyTargetSpec)
+	}
+
+	if len(errs) > 0 {
+		return "", "", fmt.Errorf("exec-spec %q: %w", spec, errors.Join(errs...))
+	}
+
+	return command, path.Clean(target), nil
+}
+
+// ExecContent runs a command via the shell, returning its standard output
+func ExecContent(ctx context.Context, command string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := util.ShellCommand(ctx, command)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// ReadTar reads a (optionally gzip-compressed) tar stream into path content.
+// Regular files become additions; OCI-style whiteout entries (`dir/.wh.name`)
+// become deletions of `dir/name`. Directories are implied and other entry types are skipped.
+func ReadTar(r io.Reader) (pathContent PathContent, deletionSet DeletionSet, err error) {
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading gzip stream: %w", err)
+		}
+		defer func() { _ = gz.Close() }()
+		r = gz
+	} else {
+		r = br
+	}
+
+	pathContent = make(PathContent)
+	deletionSet = make(DeletionSet)
+	errs := make([]error, 0)
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading tar stream: %w", err)
+		}
+
+		name := path.Clean(header.Name)
+		if name == "." {
+			continue // archive root
+		}
+		if name == ".." || strings.HasPrefix(name, "../") || path.IsAbs(name) {
+			errs = append(errs, fmt.Errorf("tar entry %q: invalid path", header.Name))
+			continue
+		}
+
+		dir, base := path.Split(name)
+
+		switch {
+		case base == whiteoutOpaque:
+			errs = append(errs, fmt.Errorf("tar entry %q: opaque whiteouts are not supported", header.Name))
+
+		case strings.HasPrefix(base, whiteoutPrefix):
+			target := path.Join(dir, strings.TrimPrefix(base, whiteoutPrefix))
+			deletionSet[target] = struct{}{}
+			delete(pathContent, target)
+
+		case header.Typeflag == tar.TypeReg:
+			content, err := io.ReadAll(tr)
+			if err != nil {
+				return nil, nil, fmt.Errorf("reading tar entry %q: %w", header.Name, err)
+			}
+			pathContent[name] = content
+			delete(deletionSet, name)
+
+		case header.Typeflag == tar.TypeDir:
+			// directories are implied by their contents
+
+		default:
+			log.Warnf("tar entry %q: skipping unsupported type %q", header.Name, header.Typeflag)
+		}
+	}
+
+	return pathContent, deletionSet, errors.Join(errs...)
+}
@@ -0,0 +1,178 @@
+package local
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestParseExecSpec(t *testing.T) {
+	tests := []struct {
+		name            string
+		spec            string
+		expectedCommand string
+		expectedTarget  string
+		wantErr         bool
+	}{
+		{
+			name:            "Simple command",
+			spec:            "date:build/timestamp.txt",
+			expectedCommand: "date",
+			expectedTarget:  "build/timestamp.txt",
+		},
+		{
+			name:            "Command containing separator",
+			spec:            "curl -s https://example.com/schema.json:schema.json",
+			expectedCommand: "curl -s https://example.com/schema.json",
+			expectedTarget:  "schema.json",
+		},
+		{
+			name:    "Missing target",
+			spec:    "date",
+			wantErr: true,
+		},
+		{
+			name:    "Empty command",
+			spec:    " :target.txt",
+			wantErr: true,
+		},
+		{
+			name:    "Empty target",
+			spec:    "date:",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, target, err := ParseExecSpec(tt.spec, ":")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseExecSpec(%q) error = %v; wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if command != tt.expectedCommand || target != tt.expectedTarget {
+				t.Errorf("ParseExecSpec(%q) = (%q, %q); expected (%q, %q)", tt.spec, command, target, tt.expectedCommand, tt.expectedTarget)
+			}
+		})
+	}
+}
+
+func TestExecContent(t *testing.T) {
+	content, err := ExecContent(context.Background(), "printf 'hello world'")
+	if err != nil {
+		t.Fatalf("ExecContent() unexpected error: %v", err)
+	}
+	if string(content) != "hello world" {
+		t.Errorf("ExecContent() = %q; expected %q", content, "hello world")
+	}
+
+	if _, err := ExecContent(context.Background(), "echo failed >&2; exit 2"); err == nil {
+		t.Error("ExecContent() expected error for failing command")
+	}
+}
+
+type tarEntry struct {
+	name     string
+	typeflag byte
+	content  string
+}
+
+func buildTar(t *testing.T, entries []tarEntry, compress bool) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	var tw *tar.Writer
+	var gz *gzip.Writer
+	if compress {
+		gz = gzip.NewWriter(&buf)
+		tw = tar.NewWriter(gz)
+	} else {
+		tw = tar.NewWriter(&buf)
+	}
+
+	for _, entry := range entries {
+		header := &tar.Header{
+			Name:     entry.name,
+			Typeflag: entry.typeflag,
+			Mode:     0o644,
+			Size:     int64(len(entry.content)),
+		}
+		if entry.typeflag == tar.TypeSymlink {
+			header.Linkname = entry.content
+			header.Size = 0
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatalf("WriteHeader(%s): %v", entry.name, err)
+		}
+		if header.Size > 0 {
+			if _, err := tw.Write([]byte(entry.content)); err != nil {
+				t.Fatalf("Write(%s): %v", entry.name, err)
+			}
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		t.Fatalf("closing tar writer: %v", err)
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			t.Fatalf("closing gzip writer: %v", err)
+		}
+	}
+
+	return buf.Bytes()
+}
+
+func TestReadTar(t *testing.T) {
+	entries := []tarEntry{
+		{name: "./", typeflag: tar.TypeDir},
+		{name: "./docs/", typeflag: tar.TypeDir},
+		{name: "./docs/index.md", typeflag: tar.TypeReg, content: "# Index"},
+		{name: "README.md", typeflag: tar.TypeReg, content: "readme"},
+		{name: "docs/.wh.old.md", typeflag: tar.TypeReg},
+		{name: "link", typeflag: tar.TypeSymlink, content: "README.md"},
+	}
+
+	for _, compress := range []bool{false, true} {
+		pathContent, deletionSet, err := ReadTar(bytes.NewReader(buildTar(t, entries, compress)))
+		if err != nil {
+			t.Fatalf("ReadTar(compress=%v) unexpected error: %v", compress, err)
+		}
+
+		if got := pathContent.Keys(); !slices.Equal(got, []string{"README.md", "docs/index.md"}) {
+			t.Errorf("ReadTar(compress=%v) additions = %v", compress, got)
+		}
+		if string(pathContent["docs/index.md"]) != "# Index" {
+			t.Errorf("ReadTar(compress=%v) docs/index.md = %q", compress, pathContent["docs/index.md"])
+		}
+		if got := deletionSet.Keys(); !slices.Equal(got, []string{"docs/old.md"}) {
+			t.Errorf("ReadTar(compress=%v) deletions = %v", compress, got)
+		}
+	}
+}
+
+func TestReadTarErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []tarEntry
+	}{
+		{
+			name:    "Path traversal",
+			entries: []tarEntry{{name: "../escape.txt", typeflag: tar.TypeReg, content: "x"}},
+		},
+		{
+			name:    "Opaque whiteout",
+			entries: []tarEntry{{name: "dir/.wh..wh..opq", typeflag: tar.TypeReg}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := ReadTar(bytes.NewReader(buildTar(t, tt.entries, false))); err == nil {
+				t.Error("ReadTar() expected error, got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package local
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/goccy/go-yaml"
+)
+
+// TemplateData is the context available to content templates
+type TemplateData struct {
+	Repository string
+	Owner      string
+	Name       string
+	Branch     string
+	BaseBranch string
+	BaseSHA    string
+	Path       string
+	Values     map[string]any
+	Vars       map[string]string
+	Env        map[string]string
+}
+
+// PullRequestData is the context available to pull request body templates
+type PullRequestData struct {
+	Repository string
+	Owner      string
+	Name       string
+	Branch     string
+	BaseBranch string
+	SHA        string
+	Title      string
+	RunURL     string
+	Files      []FileChange
+	Trailers   []string
+	Summary    string
+	Vars       map[string]string
+	Env        map[string]string
+}
+
+// ChangeSummary returns a Markdown list of the added, modified and deleted files
+func ChangeSummary(files []FileChange) string {
+	var summary strings.Builder
+	for _, file := range files {
+		switch file.Action {
+		case ChangeAdded, ChangeModified, ChangeDeleted:
+			fmt.Fprintf(&summary, "- `%s` (%s)\n", file.Path, file.Action)
+		}
+	}
+	return summary.String()
+}
+
+// LoadTemplateValues loads and merges YAML or JSON values files, later files taking precedence
+func LoadTemplateValues(paths []string) (values map[string]any, err error) {
+	values = make(map[string]any)
+
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("ReadFile(%s): %w", path, err)
+		}
+
+		fileValues := make(map[string]any)
+		if err := yaml.Unmarshal(content, &fileValues); err != nil {
+			return nil, fmt.Errorf("parsing values file %q: %w", path, err)
+		}
+
+		maps.Copy(values, fileValues)
+	}
+
+	return values, nil
+}
+
+// TemplateEnvPrefix marks the environment variables exposed to templates, which may be committed
+// or published, so that secrets such as tokens are never exposed implicitly
+const TemplateEnvPrefix = "GHUP_ENV_"
+
+// TemplateEnv returns the environment variables with TemplateEnvPrefix, keyed without the prefix
+func TemplateEnv(environ []string) map[string]string {
+	env := make(map[string]string)
+	for _, kv := range environ {
+		key, value, ok := strings.Cut(kv, "=")
+		if name, found := strings.CutPrefix(key, TemplateEnvPrefix); ok && found && name != "" {
+			env[name] = value
+		}
+	}
+	return env
+}
+
+// RenderTemplate renders content as a text/template with the given data.
+// Missing map keys are treated as errors, so that typos fail loudly.
+func RenderTemplate(name string, content []byte, data any) ([]byte, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("parsing template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("rendering template: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
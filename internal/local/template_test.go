@@ -0,0 +1,135 @@
+package local
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadTemplateValues(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	base := filepath.Join(tmpDir, "base.yaml")
+	if err := os.WriteFile(base, []byte("replicas: 1\nimage: app:latest\n"), 0o600); err != nil {
+		t.Fatalf("writing values file: %v", err)
+	}
+
+	override := filepath.Join(tmpDir, "prod.json")
+	if err := os.WriteFile(override, []byte(`{"replicas": 3}`), 0o600); err != nil {
+		t.Fatalf("writing values file: %v", err)
+	}
+
+	values, err := LoadTemplateValues([]string{base, override})
+	if err != nil {
+		t.Fatalf("LoadTemplateValues() unexpected error: %v", err)
+	}
+
+	if got := values["replicas"]; got != uint64(3) {
+		t.Errorf("values[replicas] = %v (%T); expected 3", got, got)
+	}
+	if got := values["image"]; got != "app:latest" {
+		t.Errorf("values[image] = %v; expected app:latest", got)
+	}
+
+	if _, err := LoadTemplateValues([]string{filepath.Join(tmpDir, "missing.yaml")}); err == nil {
+		t.Error("LoadTemplateValues() expected error for missing file")
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	data := TemplateData{
+		Repository: "owner/repo",
+		Branch:     "deploy/prod",
+		BaseSHA:    "abc123",
+		Values:     map[string]any{"replicas": 3},
+		Vars:       map[string]string{"env": "prod"},
+		Env:        map[string]string{"HOME": "/home/ghup"},
+	}
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "Plain content",
+			content:  "no templating here\n",
+			expected: "no templating here\n",
+		},
+		{
+			name:     "Context fields",
+			content:  "{{ .Repository }}@{{ .Branch }} from {{ .BaseSHA }}",
+			expected: "owner/repo@deploy/prod from abc123",
+		},
+		{
+			name:     "Values, vars and env",
+			content:  "{{ .Vars.env }}: {{ .Values.replicas }} ({{ .Env.HOME }})",
+			expected: "prod: 3 (/home/ghup)",
+		},
+		{
+			name:    "Missing key",
+			content: "{{ .Vars.missing }}",
+			wantErr: true,
+		},
+		{
+			name:    "Parse error",
+			content: "{{ .Branch ",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := RenderTemplate(tt.name, []byte(tt.content), data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RenderTemplate() error = %v; wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && string(result) != tt.expected {
+				t.Errorf("RenderTemplate() = %q; expected %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestChangeSummary(t *testing.T) {
+	files := []FileChange{
+		{Path: "a.txt", Action: ChangeAdded},
+		{Path: "b.txt", Action: ChangeUnchanged},
+		{Path: "c.txt", Action: ChangeModified},
+		{Path: "d.txt", Action: ChangeSkipped},
+		{Path: "e.txt", Action: ChangeDeleted},
+	}
+
+	expected := "- `a.txt` (added)\n- `c.txt` (modified)\n- `e.txt` (deleted)\n"
+	if result := ChangeSummary(files); result != expected {
+		t.Errorf("ChangeSummary() = %q; expected %q", result, expected)
+	}
+
+	if result := ChangeSummary(nil); result != "" {
+		t.Errorf("ChangeSummary(nil) = %q; expected empty", result)
+	}
+}
+
+func TestTemplateEnv(t *testing.T) {
+	environ := []string{
+		"GHUP_TOKEN=ghp_secret",
+		"GITHUB_TOKEN=ghs_secret",
+		"HOME=/home/ghup",
+		"GHUP_ENV_VERSION=1.2.0",
+		"GHUP_ENV_GREETING=a=b",
+		"GHUP_ENV_=ignored",
+		"GHUP_ENV_EMPTY=",
+	}
+
+	expected := map[string]string{
+		"VERSION":  "1.2.0",
+		"GREETING": "a=b",
+		"EMPTY":    "",
+	}
+
+	if env := TemplateEnv(environ); !maps.Equal(env, expected) {
+		t.Errorf("TemplateEnv() = %v; expected %v", env, expected)
+	}
+}
@@ -0,0 +1,143 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+
+	"github.com/nexthink-oss/ghup/internal/util"
+)
+
+const (
+	EventSuccess = "success"
+	EventFailure = "failure"
+
+	SignatureHeader = "X-Ghup-Signature-256"
+	EventHeader     = "X-Ghup-Event"
+	CommandHeader   = "X-Ghup-Command"
+
+	waitDelay = time.Second
+)
+
+// Notifier delivers a command's JSON output to exec or HTTP webhook targets.
+// Targets starting with http:// or https:// receive a POST; anything else is run
+// as a shell command with the payload on stdin.
+type Notifier struct {
+	Targets []string
+	Secret  string
+	Timeout time.Duration
+	Client  *http.Client
+}
+
+// Notify delivers the payload to every target, returning one error per failed target
+func (n *Notifier) Notify(ctx context.Context, event, command string, payload []byte) (errs []error) {
+	for _, target := range n.Targets {
+		if target == "" {
+			continue
+		}
+
+		var err error
+		if IsURL(target) {
+			log.Debugf("posting %s notification to %s", event, target)
+			err = n.post(ctx, target, event, command, payload)
+		} else {
+			log.Debugf("running %s notification command: %s", event, target)
+			err = n.exec(ctx, target, event, command, payload)
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s hook %q: %w", event, target, err))
+		}
+	}
+
+	return errs
+}
+
+// IsURL reports whether a notification target is an HTTP webhook
+func IsURL(target string) bool {
+	return strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://")
+}
+
+// Sign returns the hex-encoded HMAC-SHA256 signature of the payload, as sent in SignatureHeader
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (n *Notifier) post(ctx context.Context, url, event, command string, payload []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, n.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event)
+	req.Header.Set(CommandHeader, command)
+	if n.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(n.Secret, payload))
+	}
+
+	client := n.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	return nil
+}
+
+func (n *Notifier) exec(ctx context.Context, commandLine, event, command string, payload []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, n.Timeout)
+	defer cancel()
+
+	cmd := util.ShellCommand(ctx, commandLine)
+
+	// don't wait indefinitely for orphaned grandchildren holding stderr open
+	cmd.WaitDelay = waitDelay
+
+	var stdout, stderr bytes.Buffer
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("GHUP_HOOK_EVENT=%s", event),
+		fmt.Sprintf("GHUP_HOOK_COMMAND=%s", command),
+	)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = &stdout // keep stdout clean for ghup's own output
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if out := strings.TrimSpace(stdout.String()); out != "" {
+		log.Debugf("hook output: %s", out)
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+
+	return nil
+}
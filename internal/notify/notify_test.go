@@ -0,0 +1,112 @@
+package notify
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestIsURL(t *testing.T) {
+	tests := []struct {
+		target   string
+		expected bool
+	}{
+		{"https://example.com/hook", true},
+		{"http://localhost:8080", true},
+		{"curl https://example.com", false},
+		{"./notify.sh", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			if result := IsURL(tt.target); result != tt.expected {
+				t.Errorf("IsURL(%q) = %v; expected %v", tt.target, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	// matches `printf '{"ok":true}' | openssl dgst -sha256 -hmac secret`
+	expected := "sha256=f6b4a2841c93f8bf2fb8f2c13d8fb0b6c8e8019f09ee405d248daa8385fad638"
+	if result := Sign("secret", []byte(`{"ok":true}`)); result != expected {
+		t.Errorf("Sign() = %q; expected %q", result, expected)
+	}
+	if Sign("secret", []byte("a")) == Sign("other", []byte("a")) {
+		t.Error("Sign() should depend on the secret")
+	}
+}
+
+func TestNotifyWebhook(t *testing.T) {
+	payload := []byte(`{"updated":true}`)
+
+	var gotBody []byte
+	var gotHeaders http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+		gotHeaders = r.Header.Clone()
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	notifier := &Notifier{
+		Targets: []string{server.URL + "/ok"},
+		Secret:  "secret",
+		Timeout: time.Second,
+	}
+
+	if errs := notifier.Notify(context.Background(), EventSuccess, "content", payload); len(errs) > 0 {
+		t.Fatalf("Notify() unexpected errors: %v", errs)
+	}
+	if string(gotBody) != string(payload) {
+		t.Errorf("webhook body = %q; expected %q", gotBody, payload)
+	}
+	if got := gotHeaders.Get(SignatureHeader); got != Sign("secret", payload) {
+		t.Errorf("%s = %q; expected %q", SignatureHeader, got, Sign("secret", payload))
+	}
+	if got := gotHeaders.Get(EventHeader); got != EventSuccess {
+		t.Errorf("%s = %q; expected %q", EventHeader, got, EventSuccess)
+	}
+	if got := gotHeaders.Get(CommandHeader); got != "content" {
+		t.Errorf("%s = %q; expected %q", CommandHeader, got, "content")
+	}
+
+	notifier.Targets = []string{server.URL + "/fail"}
+	if errs := notifier.Notify(context.Background(), EventFailure, "content", payload); len(errs) != 1 {
+		t.Errorf("Notify() returned %d errors; expected 1", len(errs))
+	}
+}
+
+func TestNotifyExec(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "payload.json")
+	payload := []byte(`{"tag":"v1.0.0"}`)
+
+	notifier := &Notifier{
+		Targets: []string{
+			"cat > " + output + "; test \"$GHUP_HOOK_EVENT\" = success",
+			"exit 3",
+			"sleep 5",
+		},
+		Timeout: 500 * time.Millisecond,
+	}
+
+	errs := notifier.Notify(context.Background(), EventSuccess, "tag", payload)
+	if len(errs) != 2 {
+		t.Errorf("Notify() returned %d errors; expected 2: %v", len(errs), errs)
+	}
+
+	got, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("reading hook output: %v", err)
+	}
+	if string(got) != string(payload) {
+		t.Errorf("hook stdin = %q; expected %q", got, payload)
+	}
+}
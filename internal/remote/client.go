@@ -1,6 +1,7 @@
 package remote
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
@@ -8,6 +9,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/apex/log"
 	"github.com/gofri/go-github-ratelimit/v2/github_ratelimit"
@@ -31,6 +33,19 @@ const (
 	AutoMergeRebase = "rebase"
 )
 
+// MergeAuto selects the first merge method allowed by the repository
+const MergeAuto = "auto"
+
+// GetMergeChoices returns the available immediate merge choices
+func GetMergeChoices() []string {
+	return []string{AutoMergeOff, MergeAuto, AutoMergeMerge, AutoMergeSquash, AutoMergeRebase}
+}
+
+// GetUpdateBranchChoices returns the available methods of updating a pull request branch with its base
+func GetUpdateBranchChoices() []string {
+	return []string{AutoMergeOff, AutoMergeMerge, AutoMergeRebase}
+}
+
 // GetAutoMergeChoices returns the available auto-merge choices
 func GetAutoMergeChoices() []string {
 	return []string{AutoMergeOff, AutoMergeMerge, AutoMergeSquash, AutoMergeRebase}
@@ -64,16 +79,36 @@ func (r *Repo) String() string {
 }
 
 type PullRequest struct {
-	RepoId        string `json:"-" yaml:"-"`
-	Id            string `json:"-" yaml:"-"`
-	Number        int    `json:"number,omitzero" yaml:"number,omitempty"`
-	Url           string `json:"url" yaml:"url"`
-	Head          string `json:"head" yaml:"head"`
-	Base          string `json:"base" yaml:"base"`
-	Draft         bool   `json:"draft" yaml:"draft"`
-	Title         string `json:"title" yaml:"title"`
-	Body          string `json:"-" yaml:"-"`
-	AutoMergeMode string `json:"auto_merge_mode,omitempty" yaml:"auto_merge_mode,omitempty"`
+	RepoId        string   `json:"-" yaml:"-"`
+	HeadRepoId    string   `json:"-" yaml:"-"` // cross-repository pull requests only
+	Id            string   `json:"-" yaml:"-"`
+	Number        int      `json:"number,omitzero" yaml:"number,omitempty"`
+	Url           string   `json:"url" yaml:"url"`
+	Head          string   `json:"head" yaml:"head"`
+	Base          string   `json:"base" yaml:"base"`
+	HeadOwner     string   `json:"head_owner,omitempty" yaml:"head_owner,omitempty"` // cross-repository pull requests only
+	Draft         bool     `json:"draft" yaml:"draft"`
+	Title         string   `json:"title" yaml:"title"`
+	Body          string   `json:"-" yaml:"-"`
+	AutoMergeMode string   `json:"auto_merge_mode,omitempty" yaml:"auto_merge_mode,omitempty"`
+	Reviewers     []string `json:"reviewers,omitempty" yaml:"reviewers,omitempty"`
+	Assignees     []string `json:"assignees,omitempty" yaml:"assignees,omitempty"`
+	Labels        []string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Milestone     string   `json:"milestone,omitempty" yaml:"milestone,omitempty"`
+	State         string   `json:"state,omitempty" yaml:"state,omitempty"`
+	MergeCommit   string   `json:"merge_commit,omitempty" yaml:"merge_commit,omitempty"`
+	WaitOutcome   string   `json:"wait_outcome,omitempty" yaml:"wait_outcome,omitempty"`
+	HeadDeleted   bool     `json:"head_deleted,omitempty" yaml:"head_deleted,omitempty"`
+	BranchUpdated bool     `json:"branch_updated,omitempty" yaml:"branch_updated,omitempty"`
+	QueuePosition int      `json:"queue_position,omitempty" yaml:"queue_position,omitempty"`
+	QueueState    string   `json:"queue_state,omitempty" yaml:"queue_state,omitempty"`
+
+	HeadSHA        string `json:"head_sha,omitempty" yaml:"head_sha,omitempty"`
+	Mergeable      string `json:"mergeable,omitempty" yaml:"mergeable,omitempty"`
+	MergeState     string `json:"merge_state,omitempty" yaml:"merge_state,omitempty"`
+	ReviewDecision string `json:"review_decision,omitempty" yaml:"review_decision,omitempty"`
+	Checks         string `json:"checks,omitempty" yaml:"checks,omitempty"`
+	RequiredChecks string `json:"required_checks,omitempty" yaml:"required_checks,omitempty"`
 }
 
 func NewClient(ctx context.Context, repo *Repo) (*Client, error) {
@@ -82,12 +117,7 @@ func NewClient(ctx context.Context, repo *Repo) (*Client, error) {
 		return nil, err
 	}
 
-	src := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: token},
-	)
-
-	httpClient := oauth2.NewClient(ctx, src)
-	rateLimiter := github_ratelimit.NewClient(httpClient.Transport)
+	rateLimiter := rateLimitedClient(ctx, token)
 
 	// rate limiting is handled by the gofri round-tripper
 	v3, err := github.NewClient(
@@ -108,6 +138,31 @@ func NewClient(ctx context.Context, repo *Repo) (*Client, error) {
 	return client, nil
 }
 
+var (
+	httpClientsMu sync.Mutex
+	httpClients   = make(map[string]*http.Client)
+)
+
+// rateLimitedClient returns a rate-limited HTTP client for token, shared between clients
+// so that concurrent operations (e.g. across repositories) observe the same rate limits
+func rateLimitedClient(ctx context.Context, token string) *http.Client {
+	httpClientsMu.Lock()
+	defer httpClientsMu.Unlock()
+
+	if httpClient, ok := httpClients[token]; ok {
+		return httpClient
+	}
+
+	src := oauth2.StaticTokenSource(
+		&oauth2.Token{AccessToken: token},
+	)
+
+	httpClient := github_ratelimit.NewClient(oauth2.NewClient(ctx, src).Transport)
+	httpClients[token] = httpClient
+
+	return httpClient
+}
+
 // ResolveToken tries to find a GitHub token in the following order:
 // 1. If the token is a file path, read the file and return the contents
 // 2. If the token is non-empty, return the token as is
@@ -128,6 +183,19 @@ func ResolveToken(token string) (string, error) {
 	return "", fmt.Errorf("unable to resolve token")
 }
 
+// GetUserIdentity resolves a GitHub login to an identity with the user's noreply email address
+func (c *Client) GetUserIdentity(login string) (*Identity, error) {
+	user, _, err := c.V3.Users.Get(c.context, login)
+	if err != nil {
+		return nil, fmt.Errorf("GetUser(%s): %w", login, err)
+	}
+
+	return &Identity{
+		Name:  cmp.Or(user.GetName(), user.GetLogin()),
+		Email: NoreplyEmail(user.GetID(), user.GetLogin()),
+	}, nil
+}
+
 func (c *Client) GetCommitURL(sha string) string {
 	return fmt.Sprintf("https://github.com/%s/%s/commit/%s", c.repo.Owner, c.repo.Name, sha)
 }
@@ -275,6 +343,25 @@ func (c *Client) GetRepositoryInfo(branch string) (repository repositoryInfo, er
 	return
 }
 
+// GetRepositoryID returns the node ID of the repository
+func (c *Client) GetRepositoryID() (string, error) {
+	var query struct {
+		Repository struct {
+			Id githubv4.String
+		} `graphql:"repository(owner: $owner, name: $repo)"`
+	}
+
+	variables := map[string]any{
+		"owner": githubv4.String(c.repo.Owner),
+		"repo":  githubv4.String(c.repo.Name),
+	}
+	if err := c.V4.Query(c.context, &query, variables); err != nil {
+		return "", fmt.Errorf("GetRepositoryID(%s): %w", c.repo, err)
+	}
+
+	return string(query.Repository.Id), nil
+}
+
 // GetFileContentV4 returns the content and hash of a file on the given branch.
 // It is limited to non-binary files, as the content is returned as a string.
 // If there is any error (including binary file)
@@ -298,7 +385,7 @@ func (c *Client) GetFileContentV4(branch string, path string) (content string, o
 	}
 	err := c.V4.Query(c.context, &query, variables)
 	if err == nil {
-		log.Warnf("Got file content for %q; binary=%v; len=%d", branchPath, bool(query.Repository.Object.Blob.IsBinary), len(query.Repository.Object.Blob.Text))
+		log.Debugf("Got file content for %q; binary=%v; len=%d", branchPath, bool(query.Repository.Object.Blob.IsBinary), len(query.Repository.Object.Blob.Text))
 		content = string(query.Repository.Object.Blob.Text)
 		ok = !bool(query.Repository.Object.Blob.IsBinary)
 	}
@@ -457,12 +544,14 @@ func (c *Client) GetTagObj(name string) (tagObj *TagObj, err error) {
 	return tagObj, nil
 }
 
-func (c *Client) CreateTag(name, message, sha string) (*github.Tag, error) {
+// CreateTag creates an annotated tag object, with optional explicit tagger identity
+func (c *Client) CreateTag(name, message, sha string, tagger *Identity) (*github.Tag, error) {
 	createTag := github.CreateTag{
 		Tag:     name,
 		Message: message,
 		Object:  sha,
 		Type:    "commit",
+		Tagger:  tagger.commitAuthor(),
 	}
 	log.Debugf("Tag: %+v", createTag)
 	tag, _, err := c.V3.Git.CreateTag(c.context, c.repo.Owner, c.repo.Name, createTag)
@@ -547,6 +636,18 @@ func (c *Client) CreateCommitOnBranchV4(input githubv4.CreateCommitOnBranchInput
 	return
 }
 
+// matchesHeadOwner reports whether a pull request from the head repository of owner, which is another
+// repository if crossRepository, is from the repository of headOwner, or from the same repository if empty
+func matchesHeadOwner(headOwner string, crossRepository bool, owner string) bool {
+	if headOwner == "" {
+		return !crossRepository
+	}
+	return crossRepository && strings.EqualFold(owner, headOwner)
+}
+
+// FindPullRequestUrl finds the open pull request from head to base, recording its identity, title, body
+// and current auto-merge mode.
+// Pull requests from forks are matched by head repository owner, if HeadOwner is given, and ignored otherwise.
 func (c *Client) FindPullRequestUrl(pullRequest *PullRequest) (found bool, err error) {
 	if pullRequest == nil {
 		return false, fmt.Errorf("pull request is nil")
@@ -556,11 +657,19 @@ func (c *Client) FindPullRequestUrl(pullRequest *PullRequest) (found bool, err e
 		Repository struct {
 			PullRequests struct {
 				Nodes []struct {
-					Id                githubv4.ID
-					Number            githubv4.Int
-					Url               githubv4.String
-					Title             githubv4.String
-					IsCrossRepository githubv4.Boolean
+					Id                  githubv4.ID
+					Number              githubv4.Int
+					Url                 githubv4.String
+					Title               githubv4.String
+					Body                githubv4.String
+					IsDraft             githubv4.Boolean
+					IsCrossRepository   githubv4.Boolean
+					HeadRepositoryOwner struct {
+						Login githubv4.String
+					}
+					AutoMergeRequest *struct {
+						MergeMethod githubv4.PullRequestMergeMethod
+					}
 				}
 				PageInfo struct {
 					EndCursor   githubv4.String
@@ -579,19 +688,25 @@ func (c *Client) FindPullRequestUrl(pullRequest *PullRequest) (found bool, err e
 	}
 
 	for {
-		if c.V4.Query(c.context, &query, variables) != nil {
+		if err = c.V4.Query(c.context, &query, variables); err != nil {
 			return false, err
 		}
 
 		for _, pr := range query.Repository.PullRequests.Nodes {
-			if pr.IsCrossRepository {
-				continue // ignore cross-repository PRs
+			if !matchesHeadOwner(pullRequest.HeadOwner, bool(pr.IsCrossRepository), string(pr.HeadRepositoryOwner.Login)) {
+				continue // ignore PRs from other repositories
 			}
 
 			pullRequest.Id = fmt.Sprintf("%s", pr.Id)
 			pullRequest.Number = int(pr.Number)
 			pullRequest.Url = string(pr.Url)
 			pullRequest.Title = string(pr.Title)
+			pullRequest.Body = string(pr.Body)
+			pullRequest.Draft = bool(pr.IsDraft)
+			pullRequest.AutoMergeMode = AutoMergeOff
+			if pr.AutoMergeRequest != nil {
+				pullRequest.AutoMergeMode = strings.ToLower(string(pr.AutoMergeRequest.MergeMethod))
+			}
 			return true, nil
 		}
 
@@ -624,12 +739,16 @@ func (c *Client) CreatePullRequestV4(pullRequest *PullRequest) (err error) {
 		Title:        githubv4.String(pullRequest.Title),
 		Body:         &body,
 	}
+	if pullRequest.HeadRepoId != "" {
+		input.HeadRepositoryID = new(githubv4.ID(pullRequest.HeadRepoId))
+	}
 
 	err = c.V4.Mutate(c.context, &mutation, input, nil)
 	if err != nil {
 		return
 	}
 
+	pullRequest.Id = fmt.Sprintf("%s", mutation.CreatePullRequest.PullRequest.Id)
 	pullRequest.Url = mutation.CreatePullRequest.PullRequest.Permalink.String()
 	pullRequest.Number = int(mutation.CreatePullRequest.PullRequest.Number)
 
@@ -639,6 +758,7 @@ func (c *Client) CreatePullRequestV4(pullRequest *PullRequest) (err error) {
 		if err != nil {
 			log.Warnf("failed to enable auto-merge for pull request #%d: %v", pullRequest.Number, err)
 			// Don't fail the entire operation if auto-merge fails
+			pullRequest.AutoMergeMode = AutoMergeOff
 			err = nil
 		}
 	}
@@ -646,12 +766,17 @@ func (c *Client) CreatePullRequestV4(pullRequest *PullRequest) (err error) {
 	return
 }
 
+// UpdatePullRequestV4 updates the title and, if non-empty, the body of a pull request, then reconciles
+// its auto-merge state with AutoMergeMode, unless empty, recording the effective auto-merge mode
 func (c *Client) UpdatePullRequestV4(pullRequest *PullRequest) error {
 	var mutation struct {
 		UpdatePullRequest struct {
 			PullRequest struct {
-				Id    githubv4.ID
-				Title githubv4.String
+				Id               githubv4.ID
+				Title            githubv4.String
+				AutoMergeRequest *struct {
+					MergeMethod githubv4.PullRequestMergeMethod
+				}
 			}
 		} `graphql:"updatePullRequest(input: $input)"`
 	}
@@ -672,15 +797,82 @@ func (c *Client) UpdatePullRequestV4(pullRequest *PullRequest) error {
 		return err
 	}
 
-	// Handle auto-merge update if specified
-	if pullRequest.AutoMergeMode != AutoMergeOff {
-		err = c.enableAutoMerge(githubv4.ID(pullRequest.Id), pullRequest.AutoMergeMode)
-		if err != nil {
+	if pullRequest.AutoMergeMode == "" {
+		return nil
+	}
+
+	current := AutoMergeOff
+	if request := mutation.UpdatePullRequest.PullRequest.AutoMergeRequest; request != nil {
+		current = strings.ToLower(string(request.MergeMethod))
+	}
+
+	disable, enable := autoMergeChanges(current, pullRequest.AutoMergeMode)
+	if disable {
+		log.Infof("disabling %s auto-merge for pull request #%d", current, pullRequest.Number)
+		if err := c.disableAutoMerge(githubv4.ID(pullRequest.Id)); err != nil {
+			return fmt.Errorf("disabling auto-merge for pull request #%d: %w", pullRequest.Number, err)
+		}
+		current = AutoMergeOff
+	}
+	if enable {
+		log.Infof("enabling %s auto-merge for pull request #%d", pullRequest.AutoMergeMode, pullRequest.Number)
+		if err := c.enableAutoMerge(githubv4.ID(pullRequest.Id), pullRequest.AutoMergeMode); err != nil {
 			log.Warnf("failed to enable auto-merge for pull request #%d: %v", pullRequest.Number, err)
 			// Don't fail the entire operation if auto-merge fails
+		} else {
+			current = pullRequest.AutoMergeMode
+		}
+	}
+	pullRequest.AutoMergeMode = current
+
+	return nil
+}
+
+// autoMergeChanges reports whether reconciling the current auto-merge mode with the desired mode requires
+// disabling and/or enabling auto-merge; a change of merge method requires both
+func autoMergeChanges(current, desired string) (disable, enable bool) {
+	if current == desired {
+		return false, false
+	}
+	return current != AutoMergeOff, desired != AutoMergeOff
+}
+
+// ClosePullRequestV4 closes a pull request, first explaining why with a comment, if given
+func (c *Client) ClosePullRequestV4(pullRequest *PullRequest, comment string) error {
+	if comment != "" {
+		var commentMutation struct {
+			AddComment struct {
+				ClientMutationID githubv4.String
+			} `graphql:"addComment(input: $input)"`
+		}
+
+		commentInput := githubv4.AddCommentInput{
+			SubjectID: githubv4.ID(pullRequest.Id),
+			Body:      githubv4.String(comment),
+		}
+
+		if err := c.V4.Mutate(c.context, &commentMutation, commentInput, nil); err != nil {
+			return fmt.Errorf("commenting on pull request #%d: %w", pullRequest.Number, err)
 		}
 	}
 
+	var mutation struct {
+		ClosePullRequest struct {
+			PullRequest struct {
+				Id    githubv4.ID
+				State githubv4.String
+			}
+		} `graphql:"closePullRequest(input: $input)"`
+	}
+
+	input := githubv4.ClosePullRequestInput{
+		PullRequestID: githubv4.ID(pullRequest.Id),
+	}
+
+	if err := c.V4.Mutate(c.context, &mutation, input, nil); err != nil {
+		return fmt.Errorf("closing pull request #%d: %w", pullRequest.Number, err)
+	}
+
 	return nil
 }
 
@@ -693,21 +885,30 @@ func (c *Client) enableAutoMerge(pullRequestId githubv4.ID, mergeMethod string)
 		} `graphql:"enablePullRequestAutoMerge(input: $input)"`
 	}
 
-	var apiMergeMethod githubv4.PullRequestMergeMethod
-	switch mergeMethod {
-	case AutoMergeMerge:
-		apiMergeMethod = githubv4.PullRequestMergeMethodMerge
-	case AutoMergeSquash:
-		apiMergeMethod = githubv4.PullRequestMergeMethodSquash
-	case AutoMergeRebase:
-		apiMergeMethod = githubv4.PullRequestMergeMethodRebase
-	default:
-		return fmt.Errorf("unsupported merge method: %s", mergeMethod)
+	method, err := apiMergeMethod(mergeMethod)
+	if err != nil {
+		return err
 	}
 
 	input := githubv4.EnablePullRequestAutoMergeInput{
 		PullRequestID: pullRequestId,
-		MergeMethod:   &apiMergeMethod,
+		MergeMethod:   &method,
+	}
+
+	return c.V4.Mutate(c.context, &mutation, input, nil)
+}
+
+func (c *Client) disableAutoMerge(pullRequestId githubv4.ID) error {
+	var mutation struct {
+		DisablePullRequestAutoMerge struct {
+			PullRequest struct {
+				Id githubv4.ID
+			}
+		} `graphql:"disablePullRequestAutoMerge(input: $input)"`
+	}
+
+	input := githubv4.DisablePullRequestAutoMergeInput{
+		PullRequestID: pullRequestId,
 	}
 
 	return c.V4.Mutate(c.context, &mutation, input, nil)
@@ -756,6 +957,28 @@ func (c *Client) UpdateRefName(refName string, targetRef *github.Reference, forc
 	return legacyRef.Object.GetSHA(), updatedRef.Object.GetSHA(), nil
 }
 
+// ListBranches returns the names of all branches
+func (c *Client) ListBranches() (branchNames []string, err error) {
+	opts := &github.BranchListOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		branches, resp, err := c.V3.Repositories.ListBranches(c.context, c.repo.Owner, c.repo.Name, opts)
+		if err != nil {
+			return nil, fmt.Errorf("ListBranches(%s): %w", c.repo, err)
+		}
+
+		for _, branch := range branches {
+			branchNames = append(branchNames, branch.GetName())
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return branchNames, nil
+}
+
 func (c *Client) GetMatchingHeads(commitish string) (headNames []string, err error) {
 	branches, _, err := c.V3.Repositories.ListBranchesHeadCommit(c.context, c.repo.Owner, c.repo.Name, commitish)
 	if err != nil {
@@ -806,6 +1029,44 @@ func (r *repositoryInfo) IsAutoMergeMethodSupported(method string) bool {
 	}
 }
 
+// EffectiveAutoMergeMode returns the requested auto-merge mode if the repository supports it, or else off
+func (r *repositoryInfo) EffectiveAutoMergeMode(mode string) string {
+	if mode == AutoMergeOff {
+		return mode
+	}
+
+	if !r.AutoMergeAllowed {
+		log.Warn("repository does not have auto-merge enabled; ignoring auto-merge")
+		return AutoMergeOff
+	}
+
+	if !r.IsAutoMergeMethodSupported(mode) {
+		log.Warnf("repository does not support auto-merge method %q; supported methods: %v; using 'off'", mode, r.GetSupportedAutoMergeMethods())
+		return AutoMergeOff
+	}
+
+	return mode
+}
+
+// SelectMergeMethod returns the preferred merge method if the repository allows it or, if the
+// preference is MergeAuto, the first allowed of merge, squash and rebase
+func (r *repositoryInfo) SelectMergeMethod(preferred string) (string, error) {
+	supported := r.GetSupportedAutoMergeMethods()[1:] // excluding off
+	if len(supported) == 0 {
+		return "", errors.New("repository allows no merge methods")
+	}
+
+	if preferred == MergeAuto {
+		return supported[0], nil
+	}
+
+	if preferred == AutoMergeOff || !r.IsAutoMergeMethodSupported(preferred) {
+		return "", fmt.Errorf("repository does not allow merge method %q (allowed: %v)", preferred, supported)
+	}
+
+	return preferred, nil
+}
+
 func (r *repositoryInfo) GetSupportedAutoMergeMethods() []string {
 	var methods []string
 	methods = append(methods, AutoMergeOff) // Always supported
@@ -0,0 +1,113 @@
+package remote
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-github/v89/github"
+	"github.com/shurcooL/githubv4"
+)
+
+// newTestClient returns a client for owner/repo whose REST and GraphQL requests are served by handler
+func newTestClient(t *testing.T, handler http.Handler) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	v3, err := github.NewClient(
+		github.WithHTTPClient(server.Client()),
+		github.WithURLs(new(server.URL+"/"), new(server.URL+"/")),
+		github.WithDisableRateLimitCheck(),
+	)
+	if err != nil {
+		t.Fatalf("NewClient(): %v", err)
+	}
+
+	return &Client{
+		context: context.Background(),
+		repo:    &Repo{Owner: "owner", Name: "repo"},
+		V3:      v3,
+		V4:      githubv4.NewEnterpriseClient(server.URL+"/graphql", server.Client()),
+	}
+}
+
+func TestMatchesHeadOwner(t *testing.T) {
+	tests := []struct {
+		name            string
+		headOwner       string
+		crossRepository bool
+		owner           string
+		expected        bool
+	}{
+		{"Same repository", "", false, "owner", true},
+		{"Fork, without head owner", "", true, "bot", false},
+		{"Fork of head owner", "bot", true, "bot", true},
+		{"Fork of head owner, differing case", "Bot", true, "bot", true},
+		{"Fork of another owner", "bot", true, "someone", false},
+		{"Same repository, with head owner", "bot", false, "bot", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := matchesHeadOwner(tt.headOwner, tt.crossRepository, tt.owner); result != tt.expected {
+				t.Errorf("matchesHeadOwner(%q, %v, %q) = %v; expected %v", tt.headOwner, tt.crossRepository, tt.owner, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFindPullRequestUrl(t *testing.T) {
+	tests := []struct {
+		name         string
+		headOwner    string
+		expectFound  bool
+		expectNumber int
+	}{
+		{
+			name:         "Pull request from the same repository",
+			expectFound:  true,
+			expectNumber: 1,
+		},
+		{
+			name:         "Pull request from a fork",
+			headOwner:    "bot",
+			expectFound:  true,
+			expectNumber: 3,
+		},
+		{
+			name:      "No pull request from the fork of another owner",
+			headOwner: "nobody",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("POST /graphql", func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`{"data": {"repository": {"pullRequests": {
+					"nodes": [
+						{"id": "PR_1", "number": 1, "url": "https://github.com/owner/repo/pull/1", "isCrossRepository": false, "headRepositoryOwner": {"login": "owner"}},
+						{"id": "PR_2", "number": 2, "url": "https://github.com/owner/repo/pull/2", "isCrossRepository": true, "headRepositoryOwner": {"login": "someone"}},
+						{"id": "PR_3", "number": 3, "url": "https://github.com/owner/repo/pull/3", "isCrossRepository": true, "headRepositoryOwner": {"login": "bot"},
+						 "autoMergeRequest": {"mergeMethod": "SQUASH"}}
+					],
+					"pageInfo": {"hasNextPage": false}
+				}}}}`))
+			})
+
+			client := newTestClient(t, mux)
+
+			pullRequest := &PullRequest{Head: "bot/deps", Base: "main", HeadOwner: tt.headOwner}
+			found, err := client.FindPullRequestUrl(pullRequest)
+			if err != nil {
+				t.Fatalf("FindPullRequestUrl() error = %v", err)
+			}
+			if found != tt.expectFound || pullRequest.Number != tt.expectNumber {
+				t.Errorf("FindPullRequestUrl() = %v, #%d; expected %v, #%d", found, pullRequest.Number, tt.expectFound, tt.expectNumber)
+			}
+		})
+	}
+}
@@ -0,0 +1,342 @@
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v89/github"
+	"github.com/shurcooL/githubv4"
+)
+
+// Actions taken upon sticky comments
+const (
+	CommentCreated   = "created"
+	CommentUpdated   = "updated"
+	CommentUnchanged = "unchanged"
+	CommentDeleted   = "deleted"
+	CommentMinimized = "minimized"
+)
+
+// Comment is a comment on an issue, pull request or commit
+type Comment struct {
+	Id         string `json:"-" yaml:"-"`
+	DatabaseId int64  `json:"-" yaml:"-"` // commit comments only
+	Url        string `json:"url" yaml:"url"`
+	Body       string `json:"-" yaml:"-"`
+	Minimized  bool   `json:"minimized" yaml:"minimized"`
+	Action     string `json:"action,omitempty" yaml:"action,omitempty"`
+}
+
+// CommentSubject identifies the issue or pull request with Number, or the commit with SHA, being commented upon
+type CommentSubject struct {
+	Number int
+	SHA    string
+	Id     string // node ID of the issue or pull request, set by FindComments
+}
+
+func (s *CommentSubject) String() string {
+	if s.SHA != "" {
+		return fmt.Sprintf("commit %s", s.SHA)
+	}
+	return fmt.Sprintf("#%d", s.Number)
+}
+
+// commentNodes is the page of comments queried on issues, pull requests and commits
+type commentNodes struct {
+	Nodes []struct {
+		Id              githubv4.ID
+		DatabaseId      githubv4.Int
+		Url             githubv4.URI
+		Body            githubv4.String
+		ViewerDidAuthor githubv4.Boolean
+		IsMinimized     githubv4.Boolean
+	}
+	PageInfo struct {
+		EndCursor   githubv4.String
+		HasNextPage githubv4.Boolean
+	}
+}
+
+// CommentMarker returns the hidden marker identifying sticky comments with key
+func CommentMarker(key string) string {
+	return fmt.Sprintf("<!-- ghup:%s -->", key)
+}
+
+// ValidateCommentMarker checks that a marker key may be embedded in an HTML comment
+func ValidateCommentMarker(key string) error {
+	switch {
+	case strings.TrimSpace(key) == "":
+		return errors.New("marker required")
+	case strings.Contains(key, "--"), strings.ContainsAny(key, ">\r\n"):
+		return fmt.Errorf("invalid marker %q: must not contain '--', '>' or line breaks", key)
+	}
+	return nil
+}
+
+// WithCommentMarker appends the marker with key to body, unless already present
+func WithCommentMarker(body, key string) string {
+	marker := CommentMarker(key)
+	if strings.Contains(body, marker) {
+		return body
+	}
+
+	body = strings.TrimRight(body, "\n")
+	if body == "" {
+		return marker
+	}
+
+	return body + "\n\n" + marker
+}
+
+// FindComments returns the comments by the authenticated actor bearing the marker with key, oldest first
+func (c *Client) FindComments(subject *CommentSubject, key string) ([]*Comment, error) {
+	marker := CommentMarker(key)
+
+	variables := map[string]any{
+		"owner":  githubv4.String(c.repo.Owner),
+		"repo":   githubv4.String(c.repo.Name),
+		"cursor": (*githubv4.String)(nil),
+	}
+
+	comments := make([]*Comment, 0)
+	for {
+		var page *commentNodes
+		var err error
+		if subject.SHA != "" {
+			page, err = c.queryCommitComments(subject, variables)
+		} else {
+			page, err = c.queryIssueComments(subject, variables)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("FindComments(%s, %s): %w", c.repo, subject, err)
+		}
+
+		for _, node := range page.Nodes {
+			if !bool(node.ViewerDidAuthor) || !strings.Contains(string(node.Body), marker) {
+				continue
+			}
+			comments = append(comments, &Comment{
+				Id:         fmt.Sprintf("%s", node.Id),
+				DatabaseId: int64(node.DatabaseId),
+				Url:        node.Url.String(),
+				Body:       string(node.Body),
+				Minimized:  bool(node.IsMinimized),
+			})
+		}
+
+		if !page.PageInfo.HasNextPage {
+			break
+		}
+		variables["cursor"] = new(page.PageInfo.EndCursor)
+	}
+
+	return comments, nil
+}
+
+// queryIssueComments queries a page of the comments of an issue or pull request, recording its node ID
+func (c *Client) queryIssueComments(subject *CommentSubject, variables map[string]any) (*commentNodes, error) {
+	var query struct {
+		Repository struct {
+			IssueOrPullRequest struct {
+				Issue struct {
+					Id       githubv4.ID
+					Comments commentNodes `graphql:"comments(first: 100, after: $cursor)"`
+				} `graphql:"... on Issue"`
+				PullRequest struct {
+					Id       githubv4.ID
+					Comments commentNodes `graphql:"comments(first: 100, after: $cursor)"`
+				} `graphql:"... on PullRequest"`
+			} `graphql:"issueOrPullRequest(number: $number)"`
+		} `graphql:"repository(owner: $owner, name: $repo)"`
+	}
+
+	variables["number"] = githubv4.Int(subject.Number)
+	if err := c.V4.Query(c.context, &query, variables); err != nil {
+		return nil, err
+	}
+
+	result := query.Repository.IssueOrPullRequest
+	if result.PullRequest.Id != nil {
+		subject.Id = fmt.Sprintf("%s", result.PullRequest.Id)
+		return &result.PullRequest.Comments, nil
+	}
+	if result.Issue.Id != nil {
+		subject.Id = fmt.Sprintf("%s", result.Issue.Id)
+		return &result.Issue.Comments, nil
+	}
+
+	return nil, fmt.Errorf("issue or pull request #%d not found", subject.Number)
+}
+
+// queryCommitComments queries a page of the comments of a commit
+func (c *Client) queryCommitComments(subject *CommentSubject, variables map[string]any) (*commentNodes, error) {
+	var query struct {
+		Repository struct {
+			Object struct {
+				Commit struct {
+					Oid      githubv4.GitObjectID
+					Comments commentNodes `graphql:"comments(first: 100, after: $cursor)"`
+				} `graphql:"... on Commit"`
+			} `graphql:"object(oid: $oid)"`
+		} `graphql:"repository(owner: $owner, name: $repo)"`
+	}
+
+	variables["oid"] = githubv4.GitObjectID(subject.SHA)
+	if err := c.V4.Query(c.context, &query, variables); err != nil {
+		return nil, err
+	}
+
+	if query.Repository.Object.Commit.Oid == "" {
+		return nil, fmt.Errorf("commit %s not found", subject.SHA)
+	}
+
+	return &query.Repository.Object.Commit.Comments, nil
+}
+
+// CreateComment comments upon an issue, pull request or commit; issues and pull requests must first
+// have been looked up with FindComments
+func (c *Client) CreateComment(subject *CommentSubject, body string) (*Comment, error) {
+	if subject.SHA != "" {
+		comment, _, err := c.V3.Repositories.CreateComment(c.context, c.repo.Owner, c.repo.Name, subject.SHA, &github.RepositoryComment{Body: &body})
+		if err != nil {
+			return nil, fmt.Errorf("CreateComment(%s, %s): %w", c.repo, subject, err)
+		}
+
+		return &Comment{
+			Id:         comment.GetNodeID(),
+			DatabaseId: comment.GetID(),
+			Url:        comment.GetHTMLURL(),
+			Body:       body,
+			Action:     CommentCreated,
+		}, nil
+	}
+
+	var mutation struct {
+		AddComment struct {
+			CommentEdge struct {
+				Node struct {
+					Id  githubv4.ID
+					Url githubv4.URI
+				}
+			}
+		} `graphql:"addComment(input: $input)"`
+	}
+
+	input := githubv4.AddCommentInput{
+		SubjectID: githubv4.ID(subject.Id),
+		Body:      githubv4.String(body),
+	}
+
+	if err := c.V4.Mutate(c.context, &mutation, input, nil); err != nil {
+		return nil, fmt.Errorf("CreateComment(%s, %s): %w", c.repo, subject, err)
+	}
+
+	node := mutation.AddComment.CommentEdge.Node
+	return &Comment{
+		Id:     fmt.Sprintf("%s", node.Id),
+		Url:    node.Url.String(),
+		Body:   body,
+		Action: CommentCreated,
+	}, nil
+}
+
+// UpdateComment replaces the body of a comment
+func (c *Client) UpdateComment(subject *CommentSubject, comment *Comment, body string) error {
+	if subject.SHA != "" {
+		if _, _, err := c.V3.Repositories.UpdateComment(c.context, c.repo.Owner, c.repo.Name, comment.DatabaseId, &github.RepositoryComment{Body: &body}); err != nil {
+			return fmt.Errorf("UpdateComment(%s, %s): %w", c.repo, comment.Url, err)
+		}
+	} else {
+		var mutation struct {
+			UpdateIssueComment struct {
+				ClientMutationID githubv4.String
+			} `graphql:"updateIssueComment(input: $input)"`
+		}
+
+		input := githubv4.UpdateIssueCommentInput{
+			ID:   githubv4.ID(comment.Id),
+			Body: githubv4.String(body),
+		}
+
+		if err := c.V4.Mutate(c.context, &mutation, input, nil); err != nil {
+			return fmt.Errorf("UpdateComment(%s, %s): %w", c.repo, comment.Url, err)
+		}
+	}
+
+	comment.Body = body
+	comment.Action = CommentUpdated
+
+	return nil
+}
+
+// DeleteComment deletes a comment
+func (c *Client) DeleteComment(subject *CommentSubject, comment *Comment) error {
+	if subject.SHA != "" {
+		if _, err := c.V3.Repositories.DeleteComment(c.context, c.repo.Owner, c.repo.Name, comment.DatabaseId); err != nil {
+			return fmt.Errorf("DeleteComment(%s, %s): %w", c.repo, comment.Url, err)
+		}
+	} else {
+		var mutation struct {
+			DeleteIssueComment struct {
+				ClientMutationID githubv4.String
+			} `graphql:"deleteIssueComment(input: $input)"`
+		}
+
+		input := githubv4.DeleteIssueCommentInput{
+			ID: githubv4.ID(comment.Id),
+		}
+
+		if err := c.V4.Mutate(c.context, &mutation, input, nil); err != nil {
+			return fmt.Errorf("DeleteComment(%s, %s): %w", c.repo, comment.Url, err)
+		}
+	}
+
+	comment.Action = CommentDeleted
+
+	return nil
+}
+
+// MinimizeComment collapses a comment as outdated or, if minimize is false, expands it
+func (c *Client) MinimizeComment(comment *Comment, minimize bool) error {
+	if minimize {
+		var mutation struct {
+			MinimizeComment struct {
+				MinimizedComment struct {
+					IsMinimized githubv4.Boolean
+				}
+			} `graphql:"minimizeComment(input: $input)"`
+		}
+
+		input := githubv4.MinimizeCommentInput{
+			SubjectID:  githubv4.ID(comment.Id),
+			Classifier: githubv4.ReportedContentClassifiersOutdated,
+		}
+
+		if err := c.V4.Mutate(c.context, &mutation, input, nil); err != nil {
+			return fmt.Errorf("MinimizeComment(%s, %s): %w", c.repo, comment.Url, err)
+		}
+
+		comment.Action = CommentMinimized
+	} else {
+		var mutation struct {
+			UnminimizeComment struct {
+				UnminimizedComment struct {
+					IsMinimized githubv4.Boolean
+				}
+			} `graphql:"unminimizeComment(input: $input)"`
+		}
+
+		input := githubv4.UnminimizeCommentInput{
+			SubjectID: githubv4.ID(comment.Id),
+		}
+
+		if err := c.V4.Mutate(c.context, &mutation, input, nil); err != nil {
+			return fmt.Errorf("UnminimizeComment(%s, %s): %w", c.repo, comment.Url, err)
+		}
+	}
+
+	comment.Minimized = minimize
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package remote
+
+import "testing"
+
+func TestValidateCommentMarker(t *testing.T) {
+	tests := []struct {
+		name        string
+		key         string
+		expectError bool
+	}{
+		{name: "Simple", key: "coverage"},
+		{name: "With spaces and punctuation", key: "plan: prod/eu-west-1"},
+		{name: "Empty", key: "", expectError: true},
+		{name: "Blank", key: "  ", expectError: true},
+		{name: "Comment terminator", key: "a-->b", expectError: true},
+		{name: "Double hyphen", key: "a--b", expectError: true},
+		{name: "Line break", key: "a\nb", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateCommentMarker(tt.key); (err != nil) != tt.expectError {
+				t.Errorf("ValidateCommentMarker(%q) error = %v; expectError %v", tt.key, err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestWithCommentMarker(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{
+			name:     "Appended",
+			body:     "Coverage: 80%",
+			expected: "Coverage: 80%\n\n<!-- ghup:coverage -->",
+		},
+		{
+			name:     "Trailing newlines trimmed",
+			body:     "Coverage: 80%\n\n",
+			expected: "Coverage: 80%\n\n<!-- ghup:coverage -->",
+		},
+		{
+			name:     "Already present",
+			body:     "<!-- ghup:coverage -->\nCoverage: 80%",
+			expected: "<!-- ghup:coverage -->\nCoverage: 80%",
+		},
+		{
+			name:     "Other marker",
+			body:     "Coverage: 80%\n\n<!-- ghup:coverage-go -->",
+			expected: "Coverage: 80%\n\n<!-- ghup:coverage-go -->\n\n<!-- ghup:coverage -->",
+		},
+		{
+			name:     "Empty body",
+			body:     "",
+			expected: "<!-- ghup:coverage -->",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if body := WithCommentMarker(tt.body, "coverage"); body != tt.expected {
+				t.Errorf("WithCommentMarker(%q) = %q; expected %q", tt.body, body, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,544 @@
+package remote
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/google/go-github/v89/github"
+	"github.com/shurcooL/githubv4"
+)
+
+// ErrBranchMoved is returned when a branch no longer points to the commit expected before force-updating it
+var ErrBranchMoved = errors.New("branch moved")
+
+// Identity represents an explicit git author, committer or tagger
+type Identity struct {
+	Name  string     `json:"name" yaml:"name"`
+	Email string     `json:"email" yaml:"email"`
+	Date  *time.Time `json:"date,omitempty" yaml:"date,omitempty"`
+}
+
+func (i *Identity) String() string {
+	return fmt.Sprintf("%s <%s>", i.Name, i.Email)
+}
+
+func (i *Identity) commitAuthor() *github.CommitAuthor {
+	if i == nil {
+		return nil
+	}
+
+	author := &github.CommitAuthor{
+		Name:  new(i.Name),
+		Email: new(i.Email),
+	}
+	if i.Date != nil {
+		author.Date = &github.Timestamp{Time: *i.Date}
+	}
+
+	return author
+}
+
+// GitDataCommit describes a commit to be created via the Git Data API.
+// Changes apply to BaseTree, defaulting to the tree of the first parent (if any), unless
+// a precomputed Tree is given; Force allows the branch update to discard commits, provided
+// the branch still points to ExpectedHead, and CreateBranch creates the branch rather than
+// updating it.
+type GitDataCommit struct {
+	Branch       string
+	Parents      []string
+	BaseTree     string
+	Tree         string
+	Message      string
+	Additions    []githubv4.FileAddition
+	Deletions    []githubv4.FileDeletion
+	Author       *Identity
+	Committer    *Identity
+	Force        bool
+	ExpectedHead string
+	CreateBranch bool
+}
+
+// CommitInfo describes an existing commit
+type CommitInfo struct {
+	SHA     string
+	Message string
+	Tree    string
+	Parents []string
+}
+
+// GetCommitInfo returns the message, tree and parents of a commit
+func (c *Client) GetCommitInfo(sha string) (*CommitInfo, error) {
+	commit, _, err := c.V3.Git.GetCommit(c.context, c.repo.Owner, c.repo.Name, sha)
+	if err != nil {
+		return nil, fmt.Errorf("GetCommit(%s, %s): %w", c.repo, sha, err)
+	}
+
+	info := &CommitInfo{
+		SHA:     commit.GetSHA(),
+		Message: commit.GetMessage(),
+		Tree:    commit.GetTree().GetSHA(),
+	}
+	for _, parent := range commit.Parents {
+		info.Parents = append(info.Parents, parent.GetSHA())
+	}
+
+	return info, nil
+}
+
+// IsAncestor reports whether ancestor is reachable from (or identical to) descendant
+func (c *Client) IsAncestor(ancestor, descendant string) (bool, error) {
+	comparison, _, err := c.V3.Repositories.CompareCommits(c.context, c.repo.Owner, c.repo.Name, ancestor, descendant, &github.ListOptions{PerPage: 1})
+	if err != nil {
+		return false, fmt.Errorf("CompareCommits(%s, %s...%s): %w", c.repo, ancestor, descendant, err)
+	}
+
+	switch comparison.GetStatus() {
+	case "ahead", "identical":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// CreateCommitOnBranchV3 creates a commit via the Git Data API and updates the branch to it.
+// A commit without parents is a root commit, e.g. for orphan branches.
+// Unlike CreateCommitOnBranchV4, this allows explicit author and committer identities, but the
+// resulting commit is not signed, and hence not verified, by GitHub.
+func (c *Client) CreateCommitOnBranchV3(commit GitDataCommit) (sha string, err error) {
+	treeSha := commit.Tree
+	if treeSha == "" {
+		baseTree := commit.BaseTree
+		if baseTree == "" && len(commit.Parents) > 0 {
+			parent, _, err := c.V3.Git.GetCommit(c.context, c.repo.Owner, c.repo.Name, commit.Parents[0])
+			if err != nil {
+				return "", fmt.Errorf("GetCommit(%s, %s): %w", c.repo, commit.Parents[0], err)
+			}
+			baseTree = parent.GetTree().GetSHA()
+		}
+
+		if treeSha, err = c.CreateTree(baseTree, commit.Additions, commit.Deletions); err != nil {
+			return "", err
+		}
+	}
+
+	if treeSha == "" {
+		return "", fmt.Errorf("root commit on %q requires content", commit.Branch)
+	}
+
+	sha, err = c.createCommit(commit, treeSha)
+	if err != nil {
+		return "", err
+	}
+
+	refName := fmt.Sprintf("refs/heads/%s", commit.Branch)
+
+	if commit.CreateBranch {
+		createRef := github.CreateRef{
+			Ref: refName,
+			SHA: sha,
+		}
+		if _, _, err = c.V3.Git.CreateRef(c.context, c.repo.Owner, c.repo.Name, createRef); err != nil {
+			return "", fmt.Errorf("CreateRef(%s, %s): %w", c.repo, refName, err)
+		}
+		return sha, nil
+	}
+
+	if commit.Force {
+		if err := c.ForceUpdateBranch(commit.Branch, commit.ExpectedHead, sha); err != nil {
+			return "", err
+		}
+		return sha, nil
+	}
+
+	updateRef := github.UpdateRef{
+		SHA:   sha,
+		Force: new(false),
+	}
+	if _, _, err = c.V3.Git.UpdateRef(c.context, c.repo.Owner, c.repo.Name, refName, updateRef); err != nil {
+		return "", fmt.Errorf("UpdateRef(%s, %s): %w", c.repo, refName, err)
+	}
+
+	return sha, nil
+}
+
+// ForceUpdateBranch atomically force-updates a branch to sha, provided it still points to expected,
+// so that commits pushed since the branch was checked are not discarded
+func (c *Client) ForceUpdateBranch(branch, expected, sha string) error {
+	refName := fmt.Sprintf("refs/heads/%s", branch)
+
+	if expected == "" {
+		return fmt.Errorf("force-updating %q requires its expected head", branch)
+	}
+
+	repositoryID, err := c.GetRepositoryID()
+	if err != nil {
+		return err
+	}
+
+	var mutation struct {
+		UpdateRefs struct {
+			ClientMutationId githubv4.String
+		} `graphql:"updateRefs(input: $input)"`
+	}
+
+	input := githubv4.UpdateRefsInput{
+		RepositoryID: githubv4.ID(repositoryID),
+		RefUpdates: []githubv4.RefUpdate{
+			{
+				Name:      githubv4.GitRefname(refName),
+				AfterOid:  githubv4.GitObjectID(sha),
+				BeforeOid: new(githubv4.GitObjectID(expected)),
+				Force:     new(githubv4.Boolean(true)),
+			},
+		},
+	}
+
+	if err := c.V4.Mutate(c.context, &mutation, input, nil); err != nil {
+		// the update is rejected as a whole when the branch has moved, so report where it now points
+		if current, refErr := c.GetRefOidV4(refName); refErr == nil && string(current) != expected {
+			return fmt.Errorf("%w: %q is at %s rather than %s; refusing to discard new commits", ErrBranchMoved, branch, current, expected)
+		}
+		return fmt.Errorf("UpdateRefs(%s, %s): %w", c.repo, refName, err)
+	}
+
+	return nil
+}
+
+// RebuildBranchV4 commits changes on top of parent and moves branch from expected to the new commit
+// with a single atomic ref update, so that the branch never points anywhere in between.
+// createCommitOnBranch only appends to a branch, so the commit is created on a temporary branch
+// at parent, which is deleted afterwards.
+func (c *Client) RebuildBranchV4(branch, expected, parent string, message githubv4.CommitMessage, changes githubv4.FileChanges) (oid githubv4.GitObjectID, err error) {
+	tempBranch := fmt.Sprintf("ghup-rebuild/%s-%d", parent, time.Now().UnixNano())
+	tempRef := fmt.Sprintf("refs/heads/%s", tempBranch)
+
+	createRef := github.CreateRef{
+		Ref: tempRef,
+		SHA: parent,
+	}
+	if _, _, err := c.V3.Git.CreateRef(c.context, c.repo.Owner, c.repo.Name, createRef); err != nil {
+		return "", fmt.Errorf("CreateRef(%s, %s): %w", c.repo, tempRef, err)
+	}
+	defer func() {
+		if _, deleteErr := c.V3.Git.DeleteRef(c.context, c.repo.Owner, c.repo.Name, tempRef); deleteErr != nil {
+			log.Warnf("failed to delete temporary branch %q: %v", tempBranch, deleteErr)
+		}
+	}()
+
+	input := githubv4.CreateCommitOnBranchInput{
+		Branch:          CommittableBranch(*c.repo, tempBranch),
+		Message:         message,
+		ExpectedHeadOid: githubv4.GitObjectID(parent),
+		FileChanges:     &changes,
+	}
+	if oid, _, err = c.CreateCommitOnBranchV4(input); err != nil {
+		return "", fmt.Errorf("CreateCommitOnBranch(%s, %s): %w", c.repo, tempBranch, err)
+	}
+
+	if err := c.ForceUpdateBranch(branch, expected, string(oid)); err != nil {
+		return "", err
+	}
+
+	return oid, nil
+}
+
+// regularFileMode is the tree entry mode of a non-executable file
+const regularFileMode = "100644"
+
+// CreateTree creates a tree from baseTree with the given additions and deletions applied.
+// Files already in baseTree retain their mode, e.g. executable or symbolic link, while new
+// files are regular files.
+func (c *Client) CreateTree(baseTree string, additions []githubv4.FileAddition, deletions []githubv4.FileDeletion) (sha string, err error) {
+	if len(additions)+len(deletions) == 0 {
+		// empty commit: reuse the base tree
+		return baseTree, nil
+	}
+
+	modes, err := c.treeModes(baseTree)
+	if err != nil {
+		return "", err
+	}
+
+	entries := make([]*github.TreeEntry, 0, len(additions)+len(deletions))
+
+	for _, addition := range additions {
+		blob, _, err := c.V3.Git.CreateBlob(c.context, c.repo.Owner, c.repo.Name, github.Blob{
+			Content:  new(string(addition.Contents)),
+			Encoding: new("base64"),
+		})
+		if err != nil {
+			return "", fmt.Errorf("CreateBlob(%s, %s): %w", c.repo, addition.Path, err)
+		}
+		log.Debugf("created blob %s for %q", blob.GetSHA(), addition.Path)
+
+		entries = append(entries, &github.TreeEntry{
+			Path: new(string(addition.Path)),
+			Mode: new(entryMode(modes, string(addition.Path))),
+			Type: new("blob"),
+			SHA:  blob.SHA,
+		})
+	}
+
+	for _, deletion := range deletions {
+		// a nil SHA and Content deletes the path from the base tree
+		entries = append(entries, &github.TreeEntry{
+			Path: new(string(deletion.Path)),
+			Mode: new(entryMode(modes, string(deletion.Path))),
+			Type: new("blob"),
+		})
+	}
+
+	tree, _, err := c.V3.Git.CreateTree(c.context, c.repo.Owner, c.repo.Name, baseTree, entries)
+	if err != nil {
+		return "", fmt.Errorf("CreateTree(%s, %s): %w", c.repo, baseTree, err)
+	}
+
+	return tree.GetSHA(), nil
+}
+
+// RebuildTree creates the tree resulting from applying changes to the tree of parent, returning
+// an empty SHA if it is identical to the tree of head, i.e. there is nothing to rebuild.
+func (c *Client) RebuildTree(parent, head string, additions []githubv4.FileAddition, deletions []githubv4.FileDeletion) (string, error) {
+	parentCommit, err := c.GetCommitInfo(parent)
+	if err != nil {
+		return "", err
+	}
+
+	headCommit, err := c.GetCommitInfo(head)
+	if err != nil {
+		return "", err
+	}
+
+	tree, err := c.CreateTree(parentCommit.Tree, additions, deletions)
+	if err != nil {
+		return "", err
+	}
+
+	if tree == headCommit.Tree {
+		return "", nil
+	}
+
+	return tree, nil
+}
+
+// MatchesBase reports whether the branch resulting from applying changes to parent would be a no-op
+// against base, i.e. whether base already holds every change of that branch since their merge base,
+// even if base has since advanced. Unless force, changes are known to differ from parent, so need
+// not be applied to compare them.
+func (c *Client) MatchesBase(base, parent string, additions []githubv4.FileAddition, deletions []githubv4.FileDeletion, force bool) (bool, error) {
+	numChanges := len(additions) + len(deletions)
+	if parent == base && (numChanges == 0 || !force) {
+		return numChanges == 0, nil
+	}
+
+	baseCommit, err := c.GetCommitInfo(base)
+	if err != nil {
+		return false, err
+	}
+
+	parentCommit, err := c.GetCommitInfo(parent)
+	if err != nil {
+		return false, err
+	}
+
+	tree := parentCommit.Tree
+	if numChanges > 0 {
+		if tree, err = c.CreateTree(parentCommit.Tree, additions, deletions); err != nil {
+			return false, err
+		}
+	}
+
+	if tree == baseCommit.Tree {
+		return true, nil
+	}
+
+	mergeBase, err := c.MergeBase(base, parent)
+	if err != nil || mergeBase == "" {
+		return false, err
+	}
+
+	mergeBaseCommit, err := c.GetCommitInfo(mergeBase)
+	if err != nil {
+		return false, err
+	}
+
+	return c.containsChanges(baseCommit.Tree, mergeBaseCommit.Tree, tree)
+}
+
+// MergeBase returns the best common ancestor of two commits, or an empty SHA if they have none
+func (c *Client) MergeBase(a, b string) (string, error) {
+	comparison, _, err := c.V3.Repositories.CompareCommits(c.context, c.repo.Owner, c.repo.Name, a, b, &github.ListOptions{PerPage: 1})
+	if err != nil {
+		return "", fmt.Errorf("CompareCommits(%s, %s...%s): %w", c.repo, a, b, err)
+	}
+
+	return comparison.GetMergeBaseCommit().GetSHA(), nil
+}
+
+// containsChanges reports whether tree holds every file change from fromTree to toTree
+func (c *Client) containsChanges(tree, fromTree, toTree string) (bool, error) {
+	blobs := make([]map[string]*github.TreeEntry, 0, 3)
+	for _, sha := range []string{tree, fromTree, toTree} {
+		treeBlobs, truncated, err := c.treeBlobs(sha)
+		if err != nil {
+			return false, err
+		}
+		if truncated {
+			return false, fmt.Errorf("tree %s is too large to compare", sha)
+		}
+		blobs = append(blobs, treeBlobs)
+	}
+	treeBlobs, fromBlobs, toBlobs := blobs[0], blobs[1], blobs[2]
+
+	// files added or modified are in toBlobs, and files deleted only in fromBlobs
+	for _, files := range []map[string]*github.TreeEntry{fromBlobs, toBlobs} {
+		for path := range files {
+			if !sameEntry(fromBlobs[path], toBlobs[path]) && !sameEntry(treeBlobs[path], toBlobs[path]) {
+				log.Debugf("change of %q is not in tree %s", path, tree)
+				return false, nil
+			}
+		}
+	}
+
+	return true, nil
+}
+
+// sameEntry reports whether two tree entries, either of which may be absent, hold the same file
+func sameEntry(a, b *github.TreeEntry) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.GetSHA() == b.GetSHA() && a.GetMode() == b.GetMode()
+}
+
+// RootTree creates a tree holding only the given content, returning an empty SHA if head
+// is already a root commit with an identical tree, i.e. there is nothing to replace.
+func (c *Client) RootTree(head string, additions []githubv4.FileAddition) (string, error) {
+	headCommit, err := c.GetCommitInfo(head)
+	if err != nil {
+		return "", err
+	}
+
+	tree, err := c.CreateTree("", additions, nil)
+	if err != nil {
+		return "", err
+	}
+
+	if tree == headCommit.Tree && len(headCommit.Parents) == 0 {
+		return "", nil
+	}
+
+	return tree, nil
+}
+
+// treeModes returns the modes of the files in a tree, by path
+func (c *Client) treeModes(treeSha string) (map[string]string, error) {
+	modes := make(map[string]string)
+	if treeSha == "" {
+		return modes, nil
+	}
+
+	blobs, truncated, err := c.treeBlobs(treeSha)
+	if err != nil {
+		return nil, err
+	}
+
+	if truncated {
+		log.Warnf("tree %s is too large to list in full: unlisted files are committed as regular files", treeSha)
+	}
+
+	for path, entry := range blobs {
+		modes[path] = entry.GetMode()
+	}
+
+	return modes, nil
+}
+
+// treeBlobs returns the file entries of a tree, recursively, by path, and whether the listing was truncated
+func (c *Client) treeBlobs(treeSha string) (blobs map[string]*github.TreeEntry, truncated bool, err error) {
+	tree, _, err := c.V3.Git.GetTree(c.context, c.repo.Owner, c.repo.Name, treeSha, true)
+	if err != nil {
+		return nil, false, fmt.Errorf("GetTree(%s, %s): %w", c.repo, treeSha, err)
+	}
+
+	blobs = make(map[string]*github.TreeEntry, len(tree.Entries))
+	for _, entry := range tree.Entries {
+		if entry.GetType() == "blob" {
+			blobs[entry.GetPath()] = entry
+		}
+	}
+
+	return blobs, tree.GetTruncated(), nil
+}
+
+// TreeChanges returns the content of files added or modified from baseTree to headTree, and the
+// paths of files deleted, e.g. to carry the changes of a commit over to another parent
+func (c *Client) TreeChanges(baseTree, headTree string) (contents map[string][]byte, deletions []string, err error) {
+	baseBlobs, baseTruncated, err := c.treeBlobs(baseTree)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	headBlobs, headTruncated, err := c.treeBlobs(headTree)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if baseTruncated || headTruncated {
+		return nil, nil, fmt.Errorf("comparing trees %s and %s: too large to list in full", baseTree, headTree)
+	}
+
+	contents = make(map[string][]byte)
+	for _, path := range slices.Sorted(maps.Keys(headBlobs)) {
+		sha := headBlobs[path].GetSHA()
+		if base, ok := baseBlobs[path]; ok && base.GetSHA() == sha {
+			continue
+		}
+
+		content, _, err := c.V3.Git.GetBlobRaw(c.context, c.repo.Owner, c.repo.Name, sha)
+		if err != nil {
+			return nil, nil, fmt.Errorf("GetBlobRaw(%s, %s): %w", c.repo, path, err)
+		}
+		contents[path] = content
+	}
+
+	for _, path := range slices.Sorted(maps.Keys(baseBlobs)) {
+		if _, ok := headBlobs[path]; !ok {
+			deletions = append(deletions, path)
+		}
+	}
+
+	return contents, deletions, nil
+}
+
+// entryMode returns the mode of the file at path, or that of a regular file if not in modes
+func entryMode(modes map[string]string, path string) string {
+	return cmp.Or(modes[path], regularFileMode)
+}
+
+func (c *Client) createCommit(commit GitDataCommit, treeSha string) (sha string, err error) {
+	parents := make([]*github.Commit, 0, len(commit.Parents))
+	for _, parent := range commit.Parents {
+		parents = append(parents, &github.Commit{SHA: new(parent)})
+	}
+
+	input := github.Commit{
+		Message:   new(commit.Message),
+		Tree:      &github.Tree{SHA: new(treeSha)},
+		Parents:   parents,
+		Author:    commit.Author.commitAuthor(),
+		Committer: commit.Committer.commitAuthor(),
+	}
+
+	created, _, err := c.V3.Git.CreateCommit(c.context, c.repo.Owner, c.repo.Name, input, nil)
+	if err != nil {
+		return "", fmt.Errorf("CreateCommit(%s, %s): %w", c.repo, treeSha, err)
+	}
+
+	return created.GetSHA(), nil
+}
@@ -0,0 +1,666 @@
+package remote
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shurcooL/githubv4"
+)
+
+func TestIdentityCommitAuthor(t *testing.T) {
+	date := time.Date(2024, 3, 1, 12, 34, 56, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		identity *Identity
+		wantNil  bool
+		wantDate bool
+	}{
+		{
+			name:    "Nil identity",
+			wantNil: true,
+		},
+		{
+			name:     "Identity without date",
+			identity: &Identity{Name: "John Doe", Email: "john.doe@example.com"},
+		},
+		{
+			name:     "Identity with date",
+			identity: &Identity{Name: "John Doe", Email: "john.doe@example.com", Date: &date},
+			wantDate: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			author := tt.identity.commitAuthor()
+			if tt.wantNil {
+				if author != nil {
+					t.Errorf("commitAuthor() = %+v; expected nil", author)
+				}
+				return
+			}
+			if author.GetName() != tt.identity.Name || author.GetEmail() != tt.identity.Email {
+				t.Errorf("commitAuthor() = %s <%s>; expected %s", author.GetName(), author.GetEmail(), tt.identity)
+			}
+			if tt.wantDate != (author.Date != nil) {
+				t.Errorf("commitAuthor().Date = %v; expected date: %v", author.Date, tt.wantDate)
+			}
+			if tt.wantDate && !author.Date.Time.Equal(date) {
+				t.Errorf("commitAuthor().Date = %v; expected %v", author.Date, date)
+			}
+		})
+	}
+}
+
+func TestCreateTreeModes(t *testing.T) {
+	var created struct {
+		BaseTree string `json:"base_tree"`
+		Tree     []struct {
+			Path string  `json:"path"`
+			Mode string  `json:"mode"`
+			SHA  *string `json:"sha"`
+		} `json:"tree"`
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/owner/repo/git/trees/base", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"sha": "base", "tree": [
+			{"path": "bin", "mode": "040000", "type": "tree"},
+			{"path": "bin/run.sh", "mode": "100755", "type": "blob"},
+			{"path": "latest", "mode": "120000", "type": "blob"},
+			{"path": "README.md", "mode": "100644", "type": "blob"},
+			{"path": "vendor/lib", "mode": "160000", "type": "commit"}
+		]}`))
+	})
+	mux.HandleFunc("POST /repos/owner/repo/git/blobs", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"sha": "blob"}`))
+	})
+	mux.HandleFunc("POST /repos/owner/repo/git/trees", func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&created); err != nil {
+			t.Errorf("decoding tree: %v", err)
+		}
+		_, _ = w.Write([]byte(`{"sha": "tree"}`))
+	})
+
+	client := newTestClient(t, mux)
+
+	additions := []githubv4.FileAddition{
+		{Path: "bin/run.sh", Contents: "IyEvYmluL3No"},
+		{Path: "latest", Contents: "djEuMi4w"},
+		{Path: "README.md", Contents: "UkVBRE1F"},
+		{Path: "new.txt", Contents: "bmV3"},
+	}
+	deletions := []githubv4.FileDeletion{
+		{Path: "bin/old.sh"},
+	}
+
+	sha, err := client.CreateTree("base", additions, deletions)
+	if err != nil {
+		t.Fatalf("CreateTree() error = %v", err)
+	}
+	if sha != "tree" {
+		t.Errorf("CreateTree() = %q; expected %q", sha, "tree")
+	}
+	if created.BaseTree != "base" {
+		t.Errorf("CreateTree() base_tree = %q; expected %q", created.BaseTree, "base")
+	}
+
+	expectedModes := map[string]string{
+		"bin/run.sh": "100755",
+		"latest":     "120000",
+		"README.md":  "100644",
+		"new.txt":    "100644",
+		"bin/old.sh": "100644",
+	}
+
+	if len(created.Tree) != len(expectedModes) {
+		t.Fatalf("CreateTree() created %d entries; expected %d", len(created.Tree), len(expectedModes))
+	}
+	for _, entry := range created.Tree {
+		if mode := expectedModes[entry.Path]; entry.Mode != mode {
+			t.Errorf("CreateTree() mode of %q = %s; expected %s", entry.Path, entry.Mode, mode)
+		}
+	}
+}
+
+// handleRefUpdates serves the GraphQL queries and mutations of ForceUpdateBranch and RebuildBranchV4,
+// applying each ref update with compare-and-set semantics to heads, keyed by ref name; commits
+// created by createCommitOnBranch are named after the branch they were created on
+func handleRefUpdates(t *testing.T, mux *http.ServeMux, heads map[string]string, updates *[]githubv4.RefUpdate) {
+	t.Helper()
+
+	mux.HandleFunc("POST /graphql", func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Query     string `json:"query"`
+			Variables struct {
+				RefName string          `json:"refName"`
+				Input   json.RawMessage `json:"input"`
+			} `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decoding query: %v", err)
+		}
+
+		switch {
+		case strings.Contains(request.Query, "updateRefs"):
+			var input githubv4.UpdateRefsInput
+			if err := json.Unmarshal(request.Variables.Input, &input); err != nil {
+				t.Errorf("decoding updateRefs input: %v", err)
+			}
+			if input.RepositoryID != "R_repo" {
+				t.Errorf("updateRefs repositoryId = %v; expected R_repo", input.RepositoryID)
+			}
+			for _, update := range input.RefUpdates {
+				if update.BeforeOid != nil && heads[string(update.Name)] != string(*update.BeforeOid) {
+					_, _ = w.Write([]byte(`{"data": null, "errors": [{"message": "ref has moved"}]}`))
+					return
+				}
+			}
+			for _, update := range input.RefUpdates {
+				heads[string(update.Name)] = string(update.AfterOid)
+				*updates = append(*updates, update)
+			}
+			_, _ = w.Write([]byte(`{"data": {"updateRefs": {"clientMutationId": null}}}`))
+		case strings.Contains(request.Query, "createCommitOnBranch"):
+			var input githubv4.CreateCommitOnBranchInput
+			if err := json.Unmarshal(request.Variables.Input, &input); err != nil {
+				t.Errorf("decoding createCommitOnBranch input: %v", err)
+			}
+			refName := "refs/heads/" + string(*input.Branch.BranchName)
+			if heads[refName] != string(input.ExpectedHeadOid) {
+				_, _ = w.Write([]byte(`{"data": null, "errors": [{"message": "expected head mismatch"}]}`))
+				return
+			}
+			oid := "commit-on-" + string(*input.Branch.BranchName)
+			heads[refName] = oid
+			_, _ = fmt.Fprintf(w, `{"data": {"createCommitOnBranch": {"commit": {"oid": %q, "url": "https://github.com/owner/repo/commit/%s"}}}}`, oid, oid)
+		case strings.Contains(request.Query, "ref(qualifiedName"):
+			_, _ = fmt.Fprintf(w, `{"data": {"repository": {"ref": {"target": {"oid": %q}}}}}`, heads[request.Variables.RefName])
+		default:
+			_, _ = w.Write([]byte(`{"data": {"repository": {"id": "R_repo"}}}`))
+		}
+	})
+}
+
+func TestForceUpdateBranch(t *testing.T) {
+	tests := []struct {
+		name         string
+		current      string
+		expected     string
+		expectUpdate bool
+		expectMoved  bool
+		expectError  bool
+	}{
+		{
+			name:         "Branch at expected head",
+			current:      "aaa",
+			expected:     "aaa",
+			expectUpdate: true,
+		},
+		{
+			name:        "Branch moved since checked",
+			current:     "bbb",
+			expected:    "aaa",
+			expectMoved: true,
+			expectError: true,
+		},
+		{
+			name:        "No expected head",
+			current:     "aaa",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			heads := map[string]string{"refs/heads/bot/deps": tt.current}
+			var updates []githubv4.RefUpdate
+
+			mux := http.NewServeMux()
+			handleRefUpdates(t, mux, heads, &updates)
+
+			client := newTestClient(t, mux)
+
+			err := client.ForceUpdateBranch("bot/deps", tt.expected, "ccc")
+			if (err != nil) != tt.expectError {
+				t.Fatalf("ForceUpdateBranch() error = %v; expectError %v", err, tt.expectError)
+			}
+			if errors.Is(err, ErrBranchMoved) != tt.expectMoved {
+				t.Errorf("ForceUpdateBranch() error = %v; expected ErrBranchMoved: %v", err, tt.expectMoved)
+			}
+			if updated := len(updates) > 0; updated != tt.expectUpdate {
+				t.Fatalf("ForceUpdateBranch() updated = %v; expected %v", updated, tt.expectUpdate)
+			}
+			if len(updates) == 0 {
+				return
+			}
+			update := updates[0]
+			if update.AfterOid != "ccc" || update.BeforeOid == nil || string(*update.BeforeOid) != tt.expected || update.Force == nil || !bool(*update.Force) {
+				t.Errorf("ForceUpdateBranch() update = %+v; expected forced update from %s to ccc", update, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTreeChanges(t *testing.T) {
+	tests := []struct {
+		name            string
+		headTruncated   bool
+		expectContents  map[string]string
+		expectDeletions []string
+		expectError     bool
+	}{
+		{
+			name: "Added, modified and deleted files",
+			expectContents: map[string]string{
+				"config.json":  "new config",
+				"docs/new.txt": "new file",
+			},
+			expectDeletions: []string{"old.txt"},
+		},
+		{
+			name:          "Truncated tree",
+			headTruncated: true,
+			expectError:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blobs := map[string]string{
+				"config-new": "new config",
+				"file-new":   "new file",
+			}
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /repos/owner/repo/git/trees/base", func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`{"sha": "base", "tree": [
+					{"path": "README.md", "mode": "100644", "type": "blob", "sha": "readme"},
+					{"path": "config.json", "mode": "100644", "type": "blob", "sha": "config-old"},
+					{"path": "old.txt", "mode": "100644", "type": "blob", "sha": "old"}
+				]}`))
+			})
+			mux.HandleFunc("GET /repos/owner/repo/git/trees/head", func(w http.ResponseWriter, r *http.Request) {
+				_, _ = fmt.Fprintf(w, `{"sha": "head", "truncated": %v, "tree": [
+					{"path": "README.md", "mode": "100644", "type": "blob", "sha": "readme"},
+					{"path": "config.json", "mode": "100644", "type": "blob", "sha": "config-new"},
+					{"path": "docs", "mode": "040000", "type": "tree", "sha": "docs"},
+					{"path": "docs/new.txt", "mode": "100644", "type": "blob", "sha": "file-new"}
+				]}`, tt.headTruncated)
+			})
+			mux.HandleFunc("GET /repos/owner/repo/git/blobs/{sha}", func(w http.ResponseWriter, r *http.Request) {
+				content, ok := blobs[r.PathValue("sha")]
+				if !ok {
+					t.Errorf("unexpected blob request: %s", r.PathValue("sha"))
+					http.NotFound(w, r)
+					return
+				}
+				_, _ = w.Write([]byte(content))
+			})
+
+			client := newTestClient(t, mux)
+
+			contents, deletions, err := client.TreeChanges("base", "head")
+			if (err != nil) != tt.expectError {
+				t.Fatalf("TreeChanges() error = %v; expectError %v", err, tt.expectError)
+			}
+			if err != nil {
+				return
+			}
+
+			if len(contents) != len(tt.expectContents) {
+				t.Errorf("TreeChanges() contents = %v; expected %v", contents, tt.expectContents)
+			}
+			for path, expected := range tt.expectContents {
+				if string(contents[path]) != expected {
+					t.Errorf("TreeChanges() content of %q = %q; expected %q", path, contents[path], expected)
+				}
+			}
+			if !slices.Equal(deletions, tt.expectDeletions) {
+				t.Errorf("TreeChanges() deletions = %v; expected %v", deletions, tt.expectDeletions)
+			}
+		})
+	}
+}
+
+// testCommit is a commit served by handleCommits
+type testCommit struct {
+	tree    string
+	parents []string
+}
+
+// handleCommits serves the given commits by SHA, along with empty trees, and trees created
+// from changes, which are given createdTree as SHA
+func handleCommits(mux *http.ServeMux, commits map[string]testCommit, createdTree string) {
+	mux.HandleFunc("GET /repos/owner/repo/git/commits/{sha}", func(w http.ResponseWriter, r *http.Request) {
+		commit, ok := commits[r.PathValue("sha")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		parents := make([]map[string]string, 0, len(commit.parents))
+		for _, parent := range commit.parents {
+			parents = append(parents, map[string]string{"sha": parent})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"sha":     r.PathValue("sha"),
+			"tree":    map[string]string{"sha": commit.tree},
+			"parents": parents,
+		})
+	})
+	mux.HandleFunc("GET /repos/owner/repo/git/trees/{sha}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"sha": %q, "tree": []}`, r.PathValue("sha"))
+	})
+	mux.HandleFunc("POST /repos/owner/repo/git/blobs", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"sha": "blob"}`))
+	})
+	mux.HandleFunc("POST /repos/owner/repo/git/trees", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"sha": %q}`, createdTree)
+	})
+}
+
+func TestRebuildTree(t *testing.T) {
+	additions := []githubv4.FileAddition{{Path: "lockfile", Contents: "bG9jaw=="}}
+
+	tests := []struct {
+		name        string
+		parent      string
+		additions   []githubv4.FileAddition
+		createdTree string
+		expectTree  string
+		expectError bool
+	}{
+		{
+			name:        "Changes result in a new tree",
+			additions:   additions,
+			createdTree: "rebuilt-tree",
+			expectTree:  "rebuilt-tree",
+		},
+		{
+			name:        "Changes result in the head tree",
+			additions:   additions,
+			createdTree: "head-tree",
+		},
+		{
+			name:       "No changes from parent with a different tree",
+			expectTree: "parent-tree",
+		},
+		{
+			name:        "Unknown parent",
+			parent:      "unknown",
+			additions:   additions,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			handleCommits(mux, map[string]testCommit{
+				"parent": {tree: "parent-tree"},
+				"head":   {tree: "head-tree", parents: []string{"parent"}},
+			}, tt.createdTree)
+
+			client := newTestClient(t, mux)
+
+			tree, err := client.RebuildTree(cmp.Or(tt.parent, "parent"), "head", tt.additions, nil)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("RebuildTree() error = %v; expectError %v", err, tt.expectError)
+			}
+			if tree != tt.expectTree {
+				t.Errorf("RebuildTree() = %q; expected %q", tree, tt.expectTree)
+			}
+		})
+	}
+}
+
+func TestMatchesBase(t *testing.T) {
+	additions := []githubv4.FileAddition{{Path: "config.json", Contents: "e30="}}
+
+	tests := []struct {
+		name        string
+		base        string
+		parent      string
+		additions   []githubv4.FileAddition
+		force       bool
+		createdTree string
+		expected    bool
+	}{
+		{
+			name:     "No changes on base",
+			parent:   "base",
+			expected: true,
+		},
+		{
+			name:      "Changes on base",
+			parent:    "base",
+			additions: additions,
+		},
+		{
+			name:        "Forced changes on base resulting in its tree",
+			parent:      "base",
+			additions:   additions,
+			force:       true,
+			createdTree: "base-tree",
+			expected:    true,
+		},
+		{
+			name:     "No changes on branch with the base tree",
+			parent:   "same",
+			expected: true,
+		},
+		{
+			name:   "No changes on branch with another tree",
+			parent: "ahead",
+		},
+		{
+			name:        "Changes on branch resulting in the base tree",
+			parent:      "ahead",
+			additions:   additions,
+			createdTree: "base-tree",
+			expected:    true,
+		},
+		{
+			name:        "Changes on branch resulting in another tree",
+			parent:      "ahead",
+			additions:   additions,
+			createdTree: "other-tree",
+		},
+		{
+			name:     "Base advanced past the branch's changes",
+			base:     "advanced",
+			parent:   "ahead",
+			expected: true,
+		},
+		{
+			name:        "Base advanced past the branch's changes, which are regenerated",
+			base:        "advanced",
+			parent:      "ahead",
+			additions:   additions,
+			createdTree: "ahead-tree",
+			expected:    true,
+		},
+		{
+			name:   "Base advanced without the branch's changes",
+			base:   "diverged",
+			parent: "ahead",
+		},
+	}
+
+	// trees by SHA, mapping paths to blob SHAs
+	trees := map[string]map[string]string{
+		"base-tree":     {"config.json": "v1", "README.md": "readme"},
+		"ahead-tree":    {"config.json": "v2", "README.md": "readme"},
+		"other-tree":    {"config.json": "v3", "README.md": "readme"},
+		"advanced-tree": {"config.json": "v2", "README.md": "readme", "CHANGELOG.md": "changes"},
+		"diverged-tree": {"config.json": "v1", "README.md": "readme", "CHANGELOG.md": "changes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			handleCommits(mux, map[string]testCommit{
+				"base":     {tree: "base-tree"},
+				"same":     {tree: "base-tree", parents: []string{"base"}},
+				"ahead":    {tree: "ahead-tree", parents: []string{"base"}},
+				"advanced": {tree: "advanced-tree", parents: []string{"base"}},
+				"diverged": {tree: "diverged-tree", parents: []string{"base"}},
+			}, tt.createdTree)
+			for sha, files := range trees {
+				mux.HandleFunc("GET /repos/owner/repo/git/trees/"+sha, func(w http.ResponseWriter, r *http.Request) {
+					entries := make([]map[string]string, 0, len(files))
+					for path, blob := range files {
+						entries = append(entries, map[string]string{"path": path, "type": "blob", "mode": "100644", "sha": blob})
+					}
+					_ = json.NewEncoder(w).Encode(map[string]any{"sha": sha, "tree": entries})
+				})
+			}
+			// all test branches fork from base
+			mux.HandleFunc("GET /repos/owner/repo/compare/{basehead}", func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`{"merge_base_commit": {"sha": "base"}}`))
+			})
+
+			client := newTestClient(t, mux)
+
+			matches, err := client.MatchesBase(cmp.Or(tt.base, "base"), tt.parent, tt.additions, nil, tt.force)
+			if err != nil {
+				t.Fatalf("MatchesBase() error = %v", err)
+			}
+			if matches != tt.expected {
+				t.Errorf("MatchesBase() = %v; expected %v", matches, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRootTree(t *testing.T) {
+	additions := []githubv4.FileAddition{{Path: "index.html", Contents: "PGh0bWw+"}}
+
+	tests := []struct {
+		name        string
+		head        string
+		createdTree string
+		expectTree  string
+	}{
+		{
+			name:        "Root commit with identical content",
+			head:        "root",
+			createdTree: "root-tree",
+		},
+		{
+			name:        "Root commit with other content",
+			head:        "root",
+			createdTree: "new-tree",
+			expectTree:  "new-tree",
+		},
+		{
+			name:        "Commit with parents and identical content",
+			head:        "child",
+			createdTree: "root-tree",
+			expectTree:  "root-tree",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			handleCommits(mux, map[string]testCommit{
+				"root":  {tree: "root-tree"},
+				"child": {tree: "root-tree", parents: []string{"root"}},
+			}, tt.createdTree)
+
+			client := newTestClient(t, mux)
+
+			tree, err := client.RootTree(tt.head, additions)
+			if err != nil {
+				t.Fatalf("RootTree() error = %v", err)
+			}
+			if tree != tt.expectTree {
+				t.Errorf("RootTree() = %q; expected %q", tree, tt.expectTree)
+			}
+		})
+	}
+}
+
+func TestRebuildBranchV4(t *testing.T) {
+	tests := []struct {
+		name        string
+		current     string
+		parent      string
+		expectHead  string
+		expectMoved bool
+		expectError bool
+	}{
+		{
+			name:       "Branch rebuilt on new parent",
+			current:    "aaa",
+			parent:     "base",
+			expectHead: "commit-on-ghup-rebuild/",
+		},
+		{
+			name:        "Branch moved since checked",
+			current:     "bbb",
+			parent:      "base",
+			expectHead:  "bbb",
+			expectMoved: true,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			heads := map[string]string{"refs/heads/bot/deps": tt.current}
+			var updates []githubv4.RefUpdate
+			var tempRef string
+
+			mux := http.NewServeMux()
+			handleRefUpdates(t, mux, heads, &updates)
+			mux.HandleFunc("POST /repos/owner/repo/git/refs", func(w http.ResponseWriter, r *http.Request) {
+				var createRef struct {
+					Ref string `json:"ref"`
+					SHA string `json:"sha"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&createRef); err != nil {
+					t.Errorf("decoding ref creation: %v", err)
+				}
+				if createRef.SHA != tt.parent {
+					t.Errorf("temporary branch created at %s; expected %s", createRef.SHA, tt.parent)
+				}
+				tempRef = createRef.Ref
+				heads[tempRef] = createRef.SHA
+				_, _ = fmt.Fprintf(w, `{"ref": %q, "object": {"type": "commit", "sha": %q}}`, createRef.Ref, createRef.SHA)
+			})
+			mux.HandleFunc("DELETE /repos/owner/repo/git/refs/heads/ghup-rebuild/", func(w http.ResponseWriter, r *http.Request) {
+				delete(heads, strings.TrimPrefix(r.URL.Path, "/repos/owner/repo/git/"))
+				w.WriteHeader(http.StatusNoContent)
+			})
+
+			client := newTestClient(t, mux)
+
+			_, err := client.RebuildBranchV4("bot/deps", "aaa", tt.parent, CommitMessage("rebuild"), githubv4.FileChanges{})
+			if (err != nil) != tt.expectError {
+				t.Fatalf("RebuildBranchV4() error = %v; expectError %v", err, tt.expectError)
+			}
+			if errors.Is(err, ErrBranchMoved) != tt.expectMoved {
+				t.Errorf("RebuildBranchV4() error = %v; expected ErrBranchMoved: %v", err, tt.expectMoved)
+			}
+			if head := heads["refs/heads/bot/deps"]; !strings.HasPrefix(head, tt.expectHead) {
+				t.Errorf("RebuildBranchV4() branch head = %s; expected %s", head, tt.expectHead)
+			}
+			if len(updates) > 1 {
+				t.Errorf("RebuildBranchV4() updated refs %d times; expected a single update", len(updates))
+			}
+			if _, exists := heads[tempRef]; tempRef == "" || exists {
+				t.Errorf("RebuildBranchV4() temporary branch %q was not created and deleted", tempRef)
+			}
+		})
+	}
+}
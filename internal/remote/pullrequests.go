@@ -0,0 +1,933 @@
+package remote
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/google/go-github/v89/github"
+	"github.com/shurcooL/githubv4"
+)
+
+// Outcomes of waiting for a pull request
+const (
+	WaitMerged       = "merged"
+	WaitClosed       = "closed"
+	WaitChecksFailed = "checks_failed"
+	WaitTimedOut     = "timed_out"
+	WaitDequeued     = "dequeued"
+)
+
+// defaultLabelColor is the color of labels created on demand
+const defaultLabelColor = "ededed"
+
+// mergeabilityTimeout bounds waiting for GitHub to compute the mergeability of a pull request
+const mergeabilityTimeout = 30 * time.Second
+
+// PullRequestMetadata describes the reviewers, assignees, labels and milestone of a pull request.
+// Empty fields leave the corresponding pull request attributes untouched.
+type PullRequestMetadata struct {
+	Reviewers    []string
+	Assignees    []string
+	Labels       []string
+	Milestone    string
+	CreateLabels bool
+}
+
+// IsEmpty reports whether the metadata would leave a pull request untouched
+func (m *PullRequestMetadata) IsEmpty() bool {
+	return len(m.Reviewers) == 0 && len(m.Assignees) == 0 && len(m.Labels) == 0 && m.Milestone == ""
+}
+
+// SplitReviewers separates user reviewers from `org/team` team reviewers, returning the
+// slugs of the latter, which must belong to owner
+func SplitReviewers(reviewers []string, owner string) (users, teams []string, err error) {
+	for _, reviewer := range reviewers {
+		reviewer = strings.TrimPrefix(strings.TrimSpace(reviewer), "@")
+		if reviewer == "" {
+			continue
+		}
+
+		org, team, isTeam := strings.Cut(reviewer, "/")
+		if !isTeam {
+			users = append(users, reviewer)
+			continue
+		}
+
+		if team == "" || strings.Contains(team, "/") {
+			return nil, nil, fmt.Errorf("invalid team reviewer %q: expected org/team", reviewer)
+		}
+		if !strings.EqualFold(org, owner) {
+			return nil, nil, fmt.Errorf("team reviewer %q does not belong to %q", reviewer, owner)
+		}
+		teams = append(teams, team)
+	}
+
+	return users, teams, nil
+}
+
+// ApplyPullRequestMetadata adds the given labels and assignees to a pull request, sets its milestone,
+// and requests review from any reviewers not already requested. Labels, assignees and review requests
+// are never removed, so as to preserve those added by code owners, humans or other automation.
+func (c *Client) ApplyPullRequestMetadata(pullRequest *PullRequest, metadata *PullRequestMetadata) error {
+	users, teams, err := SplitReviewers(metadata.Reviewers, c.repo.Owner)
+	if err != nil {
+		return err
+	}
+
+	if len(metadata.Labels) > 0 {
+		labels, err := c.ensureLabels(metadata.Labels, metadata.CreateLabels)
+		if err != nil {
+			return err
+		}
+		if _, _, err := c.V3.Issues.AddLabelsToIssue(c.context, c.repo.Owner, c.repo.Name, pullRequest.Number, labels); err != nil {
+			return fmt.Errorf("labelling pull request #%d: %w", pullRequest.Number, err)
+		}
+	}
+
+	if len(metadata.Assignees) > 0 {
+		if _, _, err := c.V3.Issues.AddAssignees(c.context, c.repo.Owner, c.repo.Name, pullRequest.Number, metadata.Assignees); err != nil {
+			return fmt.Errorf("assigning pull request #%d: %w", pullRequest.Number, err)
+		}
+	}
+
+	if metadata.Milestone != "" {
+		number, err := c.resolveMilestone(metadata.Milestone)
+		if err != nil {
+			return err
+		}
+		if _, _, err := c.V3.Issues.Edit(c.context, c.repo.Owner, c.repo.Name, pullRequest.Number, &github.IssueRequest{Milestone: &number}); err != nil {
+			return fmt.Errorf("updating pull request #%d: %w", pullRequest.Number, err)
+		}
+	}
+
+	if len(users) == 0 && len(teams) == 0 {
+		return nil
+	}
+
+	current, _, err := c.V3.PullRequests.Get(c.context, c.repo.Owner, c.repo.Name, pullRequest.Number)
+	if err != nil {
+		return fmt.Errorf("GetPullRequest(%s, %d): %w", c.repo, pullRequest.Number, err)
+	}
+
+	// the author cannot review their own pull request
+	author := current.GetUser().GetLogin()
+	users = slices.DeleteFunc(users, func(user string) bool {
+		if strings.EqualFold(user, author) {
+			log.Warnf("skipping review request for pull request author %q", user)
+			return true
+		}
+		return slices.ContainsFunc(current.RequestedReviewers, func(requested *github.User) bool {
+			return strings.EqualFold(user, requested.GetLogin())
+		})
+	})
+	teams = slices.DeleteFunc(teams, func(team string) bool {
+		return slices.ContainsFunc(current.RequestedTeams, func(requested *github.Team) bool {
+			return strings.EqualFold(team, requested.GetSlug())
+		})
+	})
+
+	if len(users) == 0 && len(teams) == 0 {
+		log.Debugf("all reviewers already requested for pull request #%d", pullRequest.Number)
+		return nil
+	}
+
+	request := github.ReviewersRequest{Reviewers: users, TeamReviewers: teams}
+	if _, _, err := c.V3.PullRequests.RequestReviewers(c.context, c.repo.Owner, c.repo.Name, pullRequest.Number, request); err != nil {
+		return fmt.Errorf("requesting reviewers for pull request #%d: %w", pullRequest.Number, err)
+	}
+
+	return nil
+}
+
+// ensureLabels returns the repository's names for the given labels, matched case-insensitively,
+// creating any missing labels if create is set
+func (c *Client) ensureLabels(labels []string, create bool) ([]string, error) {
+	existing := make(map[string]string)
+
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := c.V3.Issues.ListLabels(c.context, c.repo.Owner, c.repo.Name, opts)
+		if err != nil {
+			return nil, fmt.Errorf("ListLabels(%s): %w", c.repo, err)
+		}
+
+		for _, label := range page {
+			existing[strings.ToLower(label.GetName())] = label.GetName()
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	names := make([]string, 0, len(labels))
+	missing := make([]string, 0)
+
+	for _, label := range labels {
+		if name, ok := existing[strings.ToLower(label)]; ok {
+			names = append(names, name)
+			continue
+		}
+
+		if !create {
+			missing = append(missing, label)
+			continue
+		}
+
+		log.Infof("creating label %q", label)
+		newLabel := &github.Label{Name: new(label), Color: new(defaultLabelColor)}
+		if _, _, err := c.V3.Issues.CreateLabel(c.context, c.repo.Owner, c.repo.Name, newLabel); err != nil {
+			return nil, fmt.Errorf("CreateLabel(%s, %s): %w", c.repo, label, err)
+		}
+		existing[strings.ToLower(label)] = label
+		names = append(names, label)
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("labels do not exist in %s: %s", c.repo, strings.Join(missing, ", "))
+	}
+
+	return names, nil
+}
+
+// resolveMilestone returns the number of an open milestone, given either its number or title
+func (c *Client) resolveMilestone(milestone string) (int, error) {
+	if number, err := strconv.Atoi(milestone); err == nil {
+		return number, nil
+	}
+
+	opts := &github.MilestoneListOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		page, resp, err := c.V3.Issues.ListMilestones(c.context, c.repo.Owner, c.repo.Name, opts)
+		if err != nil {
+			return 0, fmt.Errorf("ListMilestones(%s): %w", c.repo, err)
+		}
+
+		for _, m := range page {
+			if m.GetTitle() == milestone {
+				return m.GetNumber(), nil
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return 0, fmt.Errorf("no open milestone %q in %s", milestone, c.repo)
+}
+
+// PullRequestStatus describes the current state of a pull request
+type PullRequestStatus struct {
+	Id          string
+	Url         string
+	Title       string
+	Head        string
+	Base        string
+	Draft       bool
+	State       string // OPEN, CLOSED or MERGED
+	HeadSHA     string
+	BaseSHA     string // current head of the base branch
+	MergeCommit string
+	Checks      string // status check rollup: SUCCESS, PENDING, FAILURE, ERROR, EXPECTED or empty
+	Required    string // state of required checks only: SUCCESS, PENDING, FAILURE or empty, if none are reported
+	Mergeable   string // MERGEABLE, CONFLICTING or UNKNOWN
+	MergeState  string // merge state status: CLEAN, BLOCKED, BEHIND, DIRTY, etc.
+	Review      string // review decision: APPROVED, CHANGES_REQUESTED, REVIEW_REQUIRED or empty
+	AutoMerge   string // auto-merge method: MERGE, SQUASH, REBASE or empty, if not enabled
+
+	QueuePosition int    // position in the merge queue, if queued
+	QueueState    string // merge queue entry state: QUEUED, AWAITING_CHECKS, MERGEABLE, UNMERGEABLE, LOCKED or empty
+}
+
+// Apply records the status in a pull request, with enumerated values in lower case
+func (s *PullRequestStatus) Apply(pullRequest *PullRequest) {
+	pullRequest.Id = s.Id
+	pullRequest.Url = s.Url
+	pullRequest.Title = s.Title
+	pullRequest.Head = s.Head
+	pullRequest.Base = s.Base
+	pullRequest.Draft = s.Draft
+	pullRequest.State = strings.ToLower(s.State)
+	pullRequest.HeadSHA = s.HeadSHA
+	pullRequest.MergeCommit = s.MergeCommit
+	pullRequest.Checks = strings.ToLower(s.Checks)
+	pullRequest.RequiredChecks = strings.ToLower(s.Required)
+	pullRequest.Mergeable = strings.ToLower(s.Mergeable)
+	pullRequest.MergeState = strings.ToLower(s.MergeState)
+	pullRequest.ReviewDecision = strings.ToLower(s.Review)
+	pullRequest.AutoMergeMode = cmp.Or(strings.ToLower(s.AutoMerge), AutoMergeOff)
+	pullRequest.QueuePosition = s.QueuePosition
+	pullRequest.QueueState = strings.ToLower(s.QueueState)
+}
+
+// GetPullRequestStatus returns the details, state, mergeability, review decision and checks of a pull request
+func (c *Client) GetPullRequestStatus(number int) (*PullRequestStatus, error) {
+	var query struct {
+		Repository struct {
+			PullRequest struct {
+				Id               githubv4.ID
+				Url              githubv4.URI
+				Mergeable        githubv4.MergeableState
+				MergeStateStatus githubv4.MergeStateStatus
+				ReviewDecision   githubv4.PullRequestReviewDecision
+				Title            githubv4.String
+				HeadRefName      githubv4.String
+				BaseRefName      githubv4.String
+				IsDraft          githubv4.Boolean
+				State            githubv4.PullRequestState
+				HeadRefOid       githubv4.GitObjectID
+				AutoMergeRequest *struct {
+					MergeMethod githubv4.PullRequestMergeMethod
+				}
+				MergeQueueEntry *struct {
+					Position githubv4.Int
+					State    githubv4.MergeQueueEntryState
+				}
+				BaseRef *struct {
+					Target struct {
+						Oid githubv4.GitObjectID
+					}
+				}
+				MergeCommit *struct {
+					Oid githubv4.GitObjectID
+				}
+				Commits struct {
+					Nodes []struct {
+						Commit struct {
+							StatusCheckRollup *struct {
+								State    githubv4.StatusState
+								Contexts struct {
+									Nodes []struct {
+										Typename githubv4.String `graphql:"__typename"`
+										CheckRun struct {
+											Status     githubv4.CheckStatusState
+											Conclusion githubv4.CheckConclusionState
+											IsRequired githubv4.Boolean `graphql:"isRequired(pullRequestNumber: $number)"`
+										} `graphql:"... on CheckRun"`
+										StatusContext struct {
+											State      githubv4.StatusState
+											IsRequired githubv4.Boolean `graphql:"isRequired(pullRequestNumber: $number)"`
+										} `graphql:"... on StatusContext"`
+									}
+								} `graphql:"contexts(first: 100)"`
+							}
+						}
+					}
+				} `graphql:"commits(last: 1)"`
+			} `graphql:"pullRequest(number: $number)"`
+		} `graphql:"repository(owner: $owner, name: $repo)"`
+	}
+
+	variables := map[string]any{
+		"owner":  githubv4.String(c.repo.Owner),
+		"repo":   githubv4.String(c.repo.Name),
+		"number": githubv4.Int(number),
+	}
+
+	if err := c.V4.Query(c.context, &query, variables); err != nil {
+		return nil, fmt.Errorf("GetPullRequestStatus(%s, %d): %w", c.repo, number, err)
+	}
+
+	pr := query.Repository.PullRequest
+	status := &PullRequestStatus{
+		Id:         fmt.Sprintf("%s", pr.Id),
+		Mergeable:  string(pr.Mergeable),
+		MergeState: string(pr.MergeStateStatus),
+		Review:     string(pr.ReviewDecision),
+		Url:        pr.Url.String(),
+		Title:      string(pr.Title),
+		Head:       string(pr.HeadRefName),
+		Base:       string(pr.BaseRefName),
+		Draft:      bool(pr.IsDraft),
+		State:      string(pr.State),
+		HeadSHA:    string(pr.HeadRefOid),
+	}
+	if pr.AutoMergeRequest != nil {
+		status.AutoMerge = string(pr.AutoMergeRequest.MergeMethod)
+	}
+	if pr.MergeQueueEntry != nil {
+		status.QueuePosition = int(pr.MergeQueueEntry.Position)
+		status.QueueState = string(pr.MergeQueueEntry.State)
+	}
+	if pr.BaseRef != nil {
+		status.BaseSHA = string(pr.BaseRef.Target.Oid)
+	}
+	if pr.MergeCommit != nil {
+		status.MergeCommit = string(pr.MergeCommit.Oid)
+	}
+	if nodes := pr.Commits.Nodes; len(nodes) > 0 && nodes[0].Commit.StatusCheckRollup != nil {
+		rollup := nodes[0].Commit.StatusCheckRollup
+		status.Checks = string(rollup.State)
+
+		contexts := make([]CheckContext, 0, len(rollup.Contexts.Nodes))
+		for _, node := range rollup.Contexts.Nodes {
+			switch node.Typename {
+			case "CheckRun":
+				contexts = append(contexts, CheckContext{
+					Required: bool(node.CheckRun.IsRequired),
+					State:    CheckRunState(node.CheckRun.Status, node.CheckRun.Conclusion),
+				})
+			case "StatusContext":
+				contexts = append(contexts, CheckContext{
+					Required: bool(node.StatusContext.IsRequired),
+					State:    node.StatusContext.State,
+				})
+			}
+		}
+		status.Required = RequiredChecksState(contexts)
+	}
+
+	return status, nil
+}
+
+// CheckContext is a check run or commit status of a pull request head commit
+type CheckContext struct {
+	Required bool
+	State    githubv4.StatusState
+}
+
+// CheckRunState maps the status and conclusion of a check run onto a commit status state
+func CheckRunState(status githubv4.CheckStatusState, conclusion githubv4.CheckConclusionState) githubv4.StatusState {
+	if status != githubv4.CheckStatusStateCompleted {
+		return githubv4.StatusStatePending
+	}
+
+	switch conclusion {
+	case githubv4.CheckConclusionStateSuccess, githubv4.CheckConclusionStateNeutral, githubv4.CheckConclusionStateSkipped:
+		return githubv4.StatusStateSuccess
+	default:
+		return githubv4.StatusStateFailure
+	}
+}
+
+// RequiredChecksState combines the states of the required checks among contexts: FAILURE if any
+// failed, else PENDING if any are pending, else SUCCESS, or empty if none are required
+func RequiredChecksState(contexts []CheckContext) string {
+	state := ""
+	for _, context := range contexts {
+		if !context.Required {
+			continue
+		}
+
+		switch context.State {
+		case githubv4.StatusStateFailure, githubv4.StatusStateError:
+			return string(githubv4.StatusStateFailure)
+		case githubv4.StatusStateSuccess:
+			state = cmp.Or(state, string(githubv4.StatusStateSuccess))
+		default:
+			state = string(githubv4.StatusStatePending)
+		}
+	}
+
+	return state
+}
+
+// WaitOutcome returns the outcome of waiting for a pull request in the given status, or an empty
+// string if it remains open with required checks pending or passed; checks that are not required
+// may fail without preventing the pull request from merging. A queued pull request is awaited until
+// merged or removed from the merge queue, which alone decides on the outcome of its checks.
+func WaitOutcome(status *PullRequestStatus, queued bool) string {
+	switch githubv4.PullRequestState(status.State) {
+	case githubv4.PullRequestStateMerged:
+		return WaitMerged
+	case githubv4.PullRequestStateClosed:
+		return WaitClosed
+	}
+
+	if queued {
+		if status.QueueState == "" {
+			return WaitDequeued
+		}
+		return ""
+	}
+
+	if githubv4.StatusState(status.Required) == githubv4.StatusStateFailure {
+		return WaitChecksFailed
+	}
+
+	return ""
+}
+
+// WaitForPullRequest polls a pull request every interval until it is merged or closed, its
+// checks fail or, if queued, it leaves the merge queue, or timeout elapses, returning the
+// outcome and last observed status
+func (c *Client) WaitForPullRequest(number int, timeout, interval time.Duration, queued bool) (string, *PullRequestStatus, error) {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		status, err := c.GetPullRequestStatus(number)
+		if err != nil {
+			return "", nil, err
+		}
+
+		if outcome := WaitOutcome(status, queued); outcome != "" {
+			return outcome, status, nil
+		}
+
+		if time.Now().Add(interval).After(deadline) {
+			return WaitTimedOut, status, nil
+		}
+
+		if queued {
+			log.Infof("waiting for pull request #%d (queue position: %d, state: %s)", number, status.QueuePosition, strings.ToLower(status.QueueState))
+		} else {
+			log.Infof("waiting for pull request #%d (checks: %s, required: %s)", number, strings.ToLower(cmp.Or(status.Checks, "none")), strings.ToLower(cmp.Or(status.Required, "none")))
+		}
+
+		select {
+		case <-c.context.Done():
+			return "", status, c.context.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// MergeReadiness reports whether a pull request in the given status may be merged now, or may
+// become mergeable once pending checks or the mergeability computation complete; otherwise,
+// it returns why the pull request cannot be merged
+func MergeReadiness(status *PullRequestStatus) (ready, pending bool, err error) {
+	switch githubv4.PullRequestState(status.State) {
+	case githubv4.PullRequestStateMerged:
+		return false, false, errors.New("already merged")
+	case githubv4.PullRequestStateClosed:
+		return false, false, errors.New("closed")
+	}
+
+	if status.Draft {
+		return false, false, errors.New("draft")
+	}
+
+	switch githubv4.MergeableState(status.Mergeable) {
+	case githubv4.MergeableStateConflicting:
+		return false, false, errors.New("merge conflicts with base branch")
+	case githubv4.MergeableStateUnknown:
+		return false, true, nil
+	}
+
+	switch githubv4.MergeStateStatus(status.MergeState) {
+	case githubv4.MergeStateStatusClean, githubv4.MergeStateStatusHasHooks, githubv4.MergeStateStatusUnstable:
+		return true, false, nil
+	case githubv4.MergeStateStatusUnknown:
+		return false, true, nil
+	case githubv4.MergeStateStatusBehind:
+		return false, false, errors.New("head branch is behind base branch")
+	case githubv4.MergeStateStatusDirty:
+		return false, false, errors.New("merge conflicts with base branch")
+	case githubv4.MergeStateStatusBlocked:
+		if githubv4.StatusState(status.Required) == githubv4.StatusStateFailure {
+			return false, false, errors.New("required checks failed")
+		}
+		switch githubv4.StatusState(status.Checks) {
+		case githubv4.StatusStatePending, githubv4.StatusStateExpected:
+			return false, true, nil
+		}
+		return false, false, errors.New("blocked by branch protection (e.g. required reviews)")
+	}
+
+	return false, false, fmt.Errorf("merge state %q", status.MergeState)
+}
+
+// mergeabilityUnknown reports whether GitHub is still computing the mergeability of a pull request
+func mergeabilityUnknown(status *PullRequestStatus) bool {
+	return githubv4.MergeableState(status.Mergeable) == githubv4.MergeableStateUnknown ||
+		githubv4.MergeStateStatus(status.MergeState) == githubv4.MergeStateStatusUnknown
+}
+
+// MergePullRequest merges a pull request with the given method as soon as it is mergeable,
+// retrying every interval for up to retry while checks are pending, and optionally deletes
+// its head branch; the merge commit and deletion are recorded in the pull request
+func (c *Client) MergePullRequest(pullRequest *PullRequest, method string, retry, interval time.Duration, deleteBranch bool) error {
+	mergeMethod, err := apiMergeMethod(method)
+	if err != nil {
+		return err
+	}
+
+	status, err := c.awaitMergeReadiness(pullRequest.Number, retry, interval)
+	if err != nil {
+		return err
+	}
+
+	var mutation struct {
+		MergePullRequest struct {
+			PullRequest struct {
+				MergeCommit *struct {
+					Oid githubv4.GitObjectID
+				}
+				HeadRef *struct {
+					Id githubv4.ID
+				}
+			}
+		} `graphql:"mergePullRequest(input: $input)"`
+	}
+
+	headOid := githubv4.GitObjectID(status.HeadSHA)
+	input := githubv4.MergePullRequestInput{
+		PullRequestID:   githubv4.ID(status.Id),
+		MergeMethod:     &mergeMethod,
+		ExpectedHeadOid: &headOid,
+	}
+
+	log.Infof("merging pull request #%d (%s)", pullRequest.Number, method)
+	if err := c.V4.Mutate(c.context, &mutation, input, nil); err != nil {
+		return fmt.Errorf("merging pull request #%d: %w", pullRequest.Number, err)
+	}
+
+	pullRequest.State = strings.ToLower(string(githubv4.PullRequestStateMerged))
+	if mergeCommit := mutation.MergePullRequest.PullRequest.MergeCommit; mergeCommit != nil {
+		pullRequest.MergeCommit = string(mergeCommit.Oid)
+	}
+
+	if !deleteBranch {
+		return nil
+	}
+
+	// the repository may already have deleted the head branch on merge
+	headRef := mutation.MergePullRequest.PullRequest.HeadRef
+	if headRef == nil {
+		log.Debugf("head branch of pull request #%d already deleted", pullRequest.Number)
+		pullRequest.HeadDeleted = true
+		return nil
+	}
+
+	var deleteMutation struct {
+		DeleteRef struct {
+			ClientMutationID githubv4.String
+		} `graphql:"deleteRef(input: $input)"`
+	}
+
+	if err := c.V4.Mutate(c.context, &deleteMutation, githubv4.DeleteRefInput{RefID: headRef.Id}, nil); err != nil {
+		// the merge itself succeeded
+		log.Warnf("failed to delete head branch of pull request #%d: %v", pullRequest.Number, err)
+		return nil
+	}
+	pullRequest.HeadDeleted = true
+
+	return nil
+}
+
+// awaitMergeReadiness polls a pull request every interval for up to retry, while its checks are
+// pending, until it may be merged, returning its status, or else why it cannot be merged
+func (c *Client) awaitMergeReadiness(number int, retry, interval time.Duration) (*PullRequestStatus, error) {
+	start := time.Now()
+	deadline := start.Add(retry)
+
+	for {
+		status, err := c.GetPullRequestStatus(number)
+		if err != nil {
+			return nil, err
+		}
+
+		ready, pending, err := MergeReadiness(status)
+		if err != nil {
+			return nil, fmt.Errorf("pull request #%d cannot be merged: %w", number, err)
+		}
+		if ready {
+			return status, nil
+		}
+		// mergeability is typically unknown for a few seconds after a pull request is updated
+		limit := deadline
+		if mergeabilityUnknown(status) && deadline.Before(start.Add(mergeabilityTimeout)) {
+			limit = start.Add(mergeabilityTimeout)
+		}
+		if !pending || time.Now().Add(interval).After(limit) {
+			return nil, fmt.Errorf("pull request #%d not yet mergeable (checks: %s)", number, strings.ToLower(cmp.Or(status.Checks, "none")))
+		}
+
+		log.Infof("pull request #%d not yet mergeable; retrying in %s", number, interval)
+
+		select {
+		case <-c.context.Done():
+			return nil, c.context.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// EnqueuePullRequest adds a pull request to the merge queue of its base branch once mergeable,
+// retrying for up to retry while checks are pending, and records its queue position and state.
+// A pull request already queued is left in place.
+func (c *Client) EnqueuePullRequest(pullRequest *PullRequest, retry, interval time.Duration, jump bool) error {
+	status, err := c.GetPullRequestStatus(pullRequest.Number)
+	if err != nil {
+		return err
+	}
+
+	if status.QueueState == "" {
+		if status, err = c.awaitMergeReadiness(pullRequest.Number, retry, interval); err != nil {
+			return err
+		}
+	}
+
+	if status.QueueState != "" {
+		log.Infof("pull request #%d already queued at position %d", pullRequest.Number, status.QueuePosition)
+		pullRequest.QueuePosition = status.QueuePosition
+		pullRequest.QueueState = strings.ToLower(status.QueueState)
+		return nil
+	}
+
+	var mutation struct {
+		EnqueuePullRequest struct {
+			MergeQueueEntry struct {
+				Position githubv4.Int
+				State    githubv4.MergeQueueEntryState
+			}
+		} `graphql:"enqueuePullRequest(input: $input)"`
+	}
+
+	input := githubv4.EnqueuePullRequestInput{
+		PullRequestID:   githubv4.ID(status.Id),
+		ExpectedHeadOid: new(githubv4.GitObjectID(status.HeadSHA)),
+	}
+	if jump {
+		input.Jump = new(githubv4.Boolean(true))
+	}
+
+	log.Infof("adding pull request #%d to the merge queue of %q", pullRequest.Number, status.Base)
+	if err := c.V4.Mutate(c.context, &mutation, input, nil); err != nil {
+		return fmt.Errorf("enqueuing pull request #%d: %w", pullRequest.Number, err)
+	}
+
+	entry := mutation.EnqueuePullRequest.MergeQueueEntry
+	pullRequest.QueuePosition = int(entry.Position)
+	pullRequest.QueueState = strings.ToLower(string(entry.State))
+
+	return nil
+}
+
+// UpdatePullRequestBranch brings the head branch of a pull request up to date with its base branch,
+// by merge or rebase, unless it already contains the base branch head. The expected head is the
+// HeadSHA of the pull request, if known, so that commits pushed meanwhile are never overwritten.
+// Its ancestry is checked via head, the client of the head repository, as a commit just pushed to
+// a fork may not yet be known to the base repository.
+func (c *Client) UpdatePullRequestBranch(pullRequest *PullRequest, method string, head *Client) error {
+	updateMethod, err := apiUpdateBranchMethod(method)
+	if err != nil {
+		return err
+	}
+
+	status, err := c.GetPullRequestStatus(pullRequest.Number)
+	if err != nil {
+		return err
+	}
+
+	if githubv4.PullRequestState(status.State) != githubv4.PullRequestStateOpen {
+		return fmt.Errorf("pull request #%d is %s", pullRequest.Number, strings.ToLower(status.State))
+	}
+
+	headSHA := cmp.Or(pullRequest.HeadSHA, status.HeadSHA)
+	pullRequest.HeadSHA = headSHA
+
+	if status.BaseSHA == "" {
+		return fmt.Errorf("pull request #%d: base branch %q not found", pullRequest.Number, status.Base)
+	}
+
+	upToDate, err := head.IsAncestor(status.BaseSHA, headSHA)
+	if err != nil {
+		return err
+	}
+	if upToDate {
+		log.Infof("pull request #%d is up to date with %q", pullRequest.Number, status.Base)
+		return nil
+	}
+
+	if githubv4.MergeableState(status.Mergeable) == githubv4.MergeableStateConflicting {
+		return fmt.Errorf("pull request #%d: %q conflicts with %q and must be updated manually", pullRequest.Number, status.Head, status.Base)
+	}
+
+	var mutation struct {
+		UpdatePullRequestBranch struct {
+			PullRequest struct {
+				HeadRefOid githubv4.GitObjectID
+			}
+		} `graphql:"updatePullRequestBranch(input: $input)"`
+	}
+
+	input := githubv4.UpdatePullRequestBranchInput{
+		PullRequestID:   githubv4.ID(status.Id),
+		ExpectedHeadOid: new(githubv4.GitObjectID(headSHA)),
+		UpdateMethod:    &updateMethod,
+	}
+
+	log.Infof("updating pull request #%d branch %q with %q by %s", pullRequest.Number, status.Head, status.Base, method)
+	if err := c.V4.Mutate(c.context, &mutation, input, nil); err != nil {
+		if strings.Contains(strings.ToLower(err.Error()), "conflict") {
+			return fmt.Errorf("pull request #%d: %q conflicts with %q and must be updated manually: %w", pullRequest.Number, status.Head, status.Base, err)
+		}
+		return fmt.Errorf("updating pull request #%d branch: %w", pullRequest.Number, err)
+	}
+
+	pullRequest.HeadSHA = string(mutation.UpdatePullRequestBranch.PullRequest.HeadRefOid)
+	pullRequest.BranchUpdated = true
+
+	return nil
+}
+
+// ReopenPullRequestV4 reopens a closed pull request
+func (c *Client) ReopenPullRequestV4(pullRequest *PullRequest) error {
+	var mutation struct {
+		ReopenPullRequest struct {
+			PullRequest struct {
+				State githubv4.PullRequestState
+			}
+		} `graphql:"reopenPullRequest(input: $input)"`
+	}
+
+	input := githubv4.ReopenPullRequestInput{
+		PullRequestID: githubv4.ID(pullRequest.Id),
+	}
+
+	if err := c.V4.Mutate(c.context, &mutation, input, nil); err != nil {
+		return fmt.Errorf("reopening pull request #%d: %w", pullRequest.Number, err)
+	}
+	pullRequest.State = strings.ToLower(string(mutation.ReopenPullRequest.PullRequest.State))
+
+	return nil
+}
+
+// SetPullRequestDraftV4 marks a pull request as ready for review or converts it to a draft
+func (c *Client) SetPullRequestDraftV4(pullRequest *PullRequest, draft bool) error {
+	if draft {
+		var mutation struct {
+			ConvertPullRequestToDraft struct {
+				PullRequest struct {
+					IsDraft githubv4.Boolean
+				}
+			} `graphql:"convertPullRequestToDraft(input: $input)"`
+		}
+
+		input := githubv4.ConvertPullRequestToDraftInput{
+			PullRequestID: githubv4.ID(pullRequest.Id),
+		}
+
+		if err := c.V4.Mutate(c.context, &mutation, input, nil); err != nil {
+			return fmt.Errorf("converting pull request #%d to draft: %w", pullRequest.Number, err)
+		}
+		pullRequest.Draft = bool(mutation.ConvertPullRequestToDraft.PullRequest.IsDraft)
+
+		return nil
+	}
+
+	var mutation struct {
+		MarkPullRequestReadyForReview struct {
+			PullRequest struct {
+				IsDraft githubv4.Boolean
+			}
+		} `graphql:"markPullRequestReadyForReview(input: $input)"`
+	}
+
+	input := githubv4.MarkPullRequestReadyForReviewInput{
+		PullRequestID: githubv4.ID(pullRequest.Id),
+	}
+
+	if err := c.V4.Mutate(c.context, &mutation, input, nil); err != nil {
+		return fmt.Errorf("marking pull request #%d ready for review: %w", pullRequest.Number, err)
+	}
+	pullRequest.Draft = bool(mutation.MarkPullRequestReadyForReview.PullRequest.IsDraft)
+
+	return nil
+}
+
+// PullRequestFilter selects pull requests by state, head and base branch, and labels
+type PullRequestFilter struct {
+	States []githubv4.PullRequestState
+	Head   string
+	Base   string
+	Labels []string
+}
+
+// ListPullRequests returns the pull requests matching a filter, most recently updated first
+func (c *Client) ListPullRequests(filter PullRequestFilter) ([]*PullRequest, error) {
+	var query struct {
+		Repository struct {
+			PullRequests struct {
+				Nodes []struct {
+					Id          githubv4.ID
+					Number      githubv4.Int
+					Url         githubv4.URI
+					Title       githubv4.String
+					HeadRefName githubv4.String
+					BaseRefName githubv4.String
+					IsDraft     githubv4.Boolean
+					State       githubv4.PullRequestState
+					HeadRefOid  githubv4.GitObjectID
+					Labels      struct {
+						Nodes []struct {
+							Name githubv4.String
+						}
+					} `graphql:"labels(first: 100)"`
+				}
+				PageInfo struct {
+					EndCursor   githubv4.String
+					HasNextPage githubv4.Boolean
+				}
+			} `graphql:"pullRequests(states: $states, headRefName: $head, baseRefName: $base, labels: $labels, first: 100, after: $cursor, orderBy: {field: UPDATED_AT, direction: DESC})"`
+		} `graphql:"repository(owner: $owner, name: $repo)"`
+	}
+
+	variables := map[string]any{
+		"owner":  githubv4.String(c.repo.Owner),
+		"repo":   githubv4.String(c.repo.Name),
+		"states": (*[]githubv4.PullRequestState)(nil),
+		"head":   (*githubv4.String)(nil),
+		"base":   (*githubv4.String)(nil),
+		"labels": (*[]githubv4.String)(nil),
+		"cursor": (*githubv4.String)(nil),
+	}
+	if len(filter.States) > 0 {
+		variables["states"] = &filter.States
+	}
+	if filter.Head != "" {
+		variables["head"] = new(githubv4.String(filter.Head))
+	}
+	if filter.Base != "" {
+		variables["base"] = new(githubv4.String(filter.Base))
+	}
+	if len(filter.Labels) > 0 {
+		labels := make([]githubv4.String, 0, len(filter.Labels))
+		for _, label := range filter.Labels {
+			labels = append(labels, githubv4.String(label))
+		}
+		variables["labels"] = &labels
+	}
+
+	pullRequests := make([]*PullRequest, 0)
+	for {
+		if err := c.V4.Query(c.context, &query, variables); err != nil {
+			return nil, fmt.Errorf("ListPullRequests(%s): %w", c.repo, err)
+		}
+
+		for _, pr := range query.Repository.PullRequests.Nodes {
+			pullRequest := &PullRequest{
+				Id:      fmt.Sprintf("%s", pr.Id),
+				Number:  int(pr.Number),
+				Url:     pr.Url.String(),
+				Title:   string(pr.Title),
+				Head:    string(pr.HeadRefName),
+				Base:    string(pr.BaseRefName),
+				Draft:   bool(pr.IsDraft),
+				State:   strings.ToLower(string(pr.State)),
+				HeadSHA: string(pr.HeadRefOid),
+			}
+			for _, label := range pr.Labels.Nodes {
+				pullRequest.Labels = append(pullRequest.Labels, string(label.Name))
+			}
+			pullRequests = append(pullRequests, pullRequest)
+		}
+
+		if !query.Repository.PullRequests.PageInfo.HasNextPage {
+			break
+		}
+		variables["cursor"] = new(query.Repository.PullRequests.PageInfo.EndCursor)
+	}
+
+	return pullRequests, nil
+}
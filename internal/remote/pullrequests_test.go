@@ -0,0 +1,475 @@
+package remote
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/shurcooL/githubv4"
+)
+
+func TestSplitReviewers(t *testing.T) {
+	tests := []struct {
+		name          string
+		reviewers     []string
+		expectedUsers []string
+		expectedTeams []string
+		expectError   bool
+	}{
+		{
+			name:          "Users and teams",
+			reviewers:     []string{"alice", "acme/platform", "@bob", "@acme/security"},
+			expectedUsers: []string{"alice", "bob"},
+			expectedTeams: []string{"platform", "security"},
+		},
+		{
+			name:          "Owner matched case-insensitively",
+			reviewers:     []string{"Acme/platform"},
+			expectedTeams: []string{"platform"},
+		},
+		{
+			name:          "Blank entries ignored",
+			reviewers:     []string{"", " alice "},
+			expectedUsers: []string{"alice"},
+		},
+		{
+			name:        "Team of another organization",
+			reviewers:   []string{"other/platform"},
+			expectError: true,
+		},
+		{
+			name:        "Empty team",
+			reviewers:   []string{"acme/"},
+			expectError: true,
+		},
+		{
+			name:        "Too many components",
+			reviewers:   []string{"acme/platform/extra"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users, teams, err := SplitReviewers(tt.reviewers, "acme")
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("SplitReviewers() expected error, got users %v, teams %v", users, teams)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SplitReviewers() unexpected error: %v", err)
+			}
+			if !slices.Equal(users, tt.expectedUsers) {
+				t.Errorf("SplitReviewers() users = %v, expected %v", users, tt.expectedUsers)
+			}
+			if !slices.Equal(teams, tt.expectedTeams) {
+				t.Errorf("SplitReviewers() teams = %v, expected %v", teams, tt.expectedTeams)
+			}
+		})
+	}
+}
+
+func TestWaitOutcome(t *testing.T) {
+	tests := []struct {
+		name            string
+		status          PullRequestStatus
+		queued          bool
+		expectedOutcome string
+	}{
+		{
+			name:            "Merged",
+			status:          PullRequestStatus{State: "MERGED", Checks: "SUCCESS"},
+			expectedOutcome: WaitMerged,
+		},
+		{
+			name:            "Closed",
+			status:          PullRequestStatus{State: "CLOSED", Checks: "FAILURE"},
+			expectedOutcome: WaitClosed,
+		},
+		{
+			name:            "Required checks failed",
+			status:          PullRequestStatus{State: "OPEN", Checks: "FAILURE", Required: "FAILURE"},
+			expectedOutcome: WaitChecksFailed,
+		},
+		{
+			name:   "Optional checks failed, required checks passed",
+			status: PullRequestStatus{State: "OPEN", Checks: "FAILURE", Required: "SUCCESS"},
+		},
+		{
+			name:   "Optional checks errored, no required checks",
+			status: PullRequestStatus{State: "OPEN", Checks: "ERROR"},
+		},
+		{
+			name:   "Checks pending",
+			status: PullRequestStatus{State: "OPEN", Checks: "PENDING"},
+		},
+		{
+			name:   "Checks passed, awaiting merge",
+			status: PullRequestStatus{State: "OPEN", Checks: "SUCCESS"},
+		},
+		{
+			name:   "No checks",
+			status: PullRequestStatus{State: "OPEN"},
+		},
+		{
+			name:            "Queued, merged",
+			status:          PullRequestStatus{State: "MERGED", Checks: "SUCCESS"},
+			queued:          true,
+			expectedOutcome: WaitMerged,
+		},
+		{
+			name:   "Queued, awaiting checks",
+			status: PullRequestStatus{State: "OPEN", Checks: "SUCCESS", QueuePosition: 2, QueueState: "AWAITING_CHECKS"},
+			queued: true,
+		},
+		{
+			name:   "Queued, head checks failed",
+			status: PullRequestStatus{State: "OPEN", Checks: "FAILURE", QueuePosition: 1, QueueState: "QUEUED"},
+			queued: true,
+		},
+		{
+			name:            "Removed from queue",
+			status:          PullRequestStatus{State: "OPEN", Checks: "SUCCESS"},
+			queued:          true,
+			expectedOutcome: WaitDequeued,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if outcome := WaitOutcome(&tt.status, tt.queued); outcome != tt.expectedOutcome {
+				t.Errorf("WaitOutcome(%+v, %t) = %q; expected %q", tt.status, tt.queued, outcome, tt.expectedOutcome)
+			}
+		})
+	}
+}
+
+func TestCheckRunState(t *testing.T) {
+	tests := []struct {
+		status     githubv4.CheckStatusState
+		conclusion githubv4.CheckConclusionState
+		expected   githubv4.StatusState
+	}{
+		{githubv4.CheckStatusStateQueued, "", githubv4.StatusStatePending},
+		{githubv4.CheckStatusStateInProgress, "", githubv4.StatusStatePending},
+		{githubv4.CheckStatusStateCompleted, githubv4.CheckConclusionStateSuccess, githubv4.StatusStateSuccess},
+		{githubv4.CheckStatusStateCompleted, githubv4.CheckConclusionStateNeutral, githubv4.StatusStateSuccess},
+		{githubv4.CheckStatusStateCompleted, githubv4.CheckConclusionStateSkipped, githubv4.StatusStateSuccess},
+		{githubv4.CheckStatusStateCompleted, githubv4.CheckConclusionStateFailure, githubv4.StatusStateFailure},
+		{githubv4.CheckStatusStateCompleted, githubv4.CheckConclusionStateTimedOut, githubv4.StatusStateFailure},
+		{githubv4.CheckStatusStateCompleted, githubv4.CheckConclusionStateCancelled, githubv4.StatusStateFailure},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status)+"|"+string(tt.conclusion), func(t *testing.T) {
+			if state := CheckRunState(tt.status, tt.conclusion); state != tt.expected {
+				t.Errorf("CheckRunState(%s, %s) = %s; expected %s", tt.status, tt.conclusion, state, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRequiredChecksState(t *testing.T) {
+	tests := []struct {
+		name     string
+		contexts []CheckContext
+		expected string
+	}{
+		{
+			name: "No checks",
+		},
+		{
+			name: "No required checks",
+			contexts: []CheckContext{
+				{State: githubv4.StatusStateFailure},
+			},
+		},
+		{
+			name: "Required checks passed, optional check failed",
+			contexts: []CheckContext{
+				{Required: true, State: githubv4.StatusStateSuccess},
+				{State: githubv4.StatusStateFailure},
+			},
+			expected: "SUCCESS",
+		},
+		{
+			name: "Required check pending",
+			contexts: []CheckContext{
+				{Required: true, State: githubv4.StatusStateSuccess},
+				{Required: true, State: githubv4.StatusStatePending},
+			},
+			expected: "PENDING",
+		},
+		{
+			name: "Required check failed",
+			contexts: []CheckContext{
+				{Required: true, State: githubv4.StatusStatePending},
+				{Required: true, State: githubv4.StatusStateError},
+			},
+			expected: "FAILURE",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if state := RequiredChecksState(tt.contexts); state != tt.expected {
+				t.Errorf("RequiredChecksState(%+v) = %q; expected %q", tt.contexts, state, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMergeReadiness(t *testing.T) {
+	tests := []struct {
+		name            string
+		status          PullRequestStatus
+		expectedReady   bool
+		expectedPending bool
+		expectError     bool
+	}{
+		{
+			name:          "Clean",
+			status:        PullRequestStatus{State: "OPEN", Mergeable: "MERGEABLE", MergeState: "CLEAN"},
+			expectedReady: true,
+		},
+		{
+			name:          "Unstable, with non-required checks failing",
+			status:        PullRequestStatus{State: "OPEN", Mergeable: "MERGEABLE", MergeState: "UNSTABLE", Checks: "FAILURE"},
+			expectedReady: true,
+		},
+		{
+			name:            "Mergeability being computed",
+			status:          PullRequestStatus{State: "OPEN", Mergeable: "UNKNOWN", MergeState: "UNKNOWN"},
+			expectedPending: true,
+		},
+		{
+			name:            "Blocked by pending checks",
+			status:          PullRequestStatus{State: "OPEN", Mergeable: "MERGEABLE", MergeState: "BLOCKED", Checks: "PENDING"},
+			expectedPending: true,
+		},
+		{
+			name:        "Blocked by failed checks",
+			status:      PullRequestStatus{State: "OPEN", Mergeable: "MERGEABLE", MergeState: "BLOCKED", Checks: "FAILURE", Required: "FAILURE"},
+			expectError: true,
+		},
+		{
+			name:        "Blocked by required reviews",
+			status:      PullRequestStatus{State: "OPEN", Mergeable: "MERGEABLE", MergeState: "BLOCKED", Checks: "SUCCESS"},
+			expectError: true,
+		},
+		{
+			name:        "Conflicting",
+			status:      PullRequestStatus{State: "OPEN", Mergeable: "CONFLICTING", MergeState: "DIRTY"},
+			expectError: true,
+		},
+		{
+			name:        "Behind base",
+			status:      PullRequestStatus{State: "OPEN", Mergeable: "MERGEABLE", MergeState: "BEHIND"},
+			expectError: true,
+		},
+		{
+			name:        "Draft",
+			status:      PullRequestStatus{State: "OPEN", Draft: true, Mergeable: "MERGEABLE", MergeState: "DRAFT"},
+			expectError: true,
+		},
+		{
+			name:        "Closed",
+			status:      PullRequestStatus{State: "CLOSED"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ready, pending, err := MergeReadiness(&tt.status)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("MergeReadiness(%+v) error = %v; expectError %v", tt.status, err, tt.expectError)
+			}
+			if ready != tt.expectedReady || pending != tt.expectedPending {
+				t.Errorf("MergeReadiness(%+v) = %v, %v; expected %v, %v", tt.status, ready, pending, tt.expectedReady, tt.expectedPending)
+			}
+		})
+	}
+}
+
+func TestApplyPullRequestMetadataPreservesExisting(t *testing.T) {
+	labels := []string{"keep-me"}
+	assignees := []string{"carol"}
+	edited := false
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/owner/repo/labels", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"name": "keep-me"}, {"name": "Dependencies"}]`))
+	})
+	mux.HandleFunc("POST /repos/owner/repo/issues/7/labels", func(w http.ResponseWriter, r *http.Request) {
+		var added []string
+		if err := json.NewDecoder(r.Body).Decode(&added); err != nil {
+			t.Errorf("decoding labels: %v", err)
+		}
+		labels = append(labels, added...)
+		_, _ = w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("POST /repos/owner/repo/issues/7/assignees", func(w http.ResponseWriter, r *http.Request) {
+		var added struct {
+			Assignees []string `json:"assignees"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&added); err != nil {
+			t.Errorf("decoding assignees: %v", err)
+		}
+		assignees = append(assignees, added.Assignees...)
+		_, _ = w.Write([]byte(`{"number": 7}`))
+	})
+	mux.HandleFunc("PATCH /repos/owner/repo/issues/7", func(w http.ResponseWriter, r *http.Request) {
+		edited = true
+		_, _ = w.Write([]byte(`{"number": 7}`))
+	})
+
+	client := newTestClient(t, mux)
+
+	metadata := &PullRequestMetadata{
+		Labels:    []string{"dependencies"},
+		Assignees: []string{"alice"},
+	}
+	if err := client.ApplyPullRequestMetadata(&PullRequest{Number: 7}, metadata); err != nil {
+		t.Fatalf("ApplyPullRequestMetadata() error = %v", err)
+	}
+
+	if expected := []string{"keep-me", "Dependencies"}; !slices.Equal(labels, expected) {
+		t.Errorf("ApplyPullRequestMetadata() labels = %v; expected %v", labels, expected)
+	}
+	if expected := []string{"carol", "alice"}; !slices.Equal(assignees, expected) {
+		t.Errorf("ApplyPullRequestMetadata() assignees = %v; expected %v", assignees, expected)
+	}
+	if edited {
+		t.Errorf("ApplyPullRequestMetadata() edited the pull request without a milestone")
+	}
+}
+
+func TestGetPullRequestStatusRequiredChecks(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /graphql", func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Query string `json:"query"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decoding query: %v", err)
+		}
+		if !strings.Contains(request.Query, "isRequired(pullRequestNumber: $number)") {
+			t.Errorf("query does not request required checks: %s", request.Query)
+		}
+		_, _ = w.Write([]byte(`{"data": {"repository": {"pullRequest": {
+			"url": "https://github.com/owner/repo/pull/7",
+			"state": "OPEN",
+			"commits": {"nodes": [{"commit": {"statusCheckRollup": {
+				"state": "FAILURE",
+				"contexts": {"nodes": [
+					{"__typename": "CheckRun", "status": "COMPLETED", "conclusion": "SUCCESS", "isRequired": true},
+					{"__typename": "CheckRun", "status": "COMPLETED", "conclusion": "FAILURE", "isRequired": false},
+					{"__typename": "StatusContext", "state": "SUCCESS", "isRequired": true}
+				]}
+			}}}]}
+		}}}}`))
+	})
+
+	client := newTestClient(t, mux)
+
+	status, err := client.GetPullRequestStatus(7)
+	if err != nil {
+		t.Fatalf("GetPullRequestStatus() error = %v", err)
+	}
+	if status.Checks != "FAILURE" || status.Required != "SUCCESS" {
+		t.Errorf("GetPullRequestStatus() checks = %q, required = %q; expected FAILURE, SUCCESS", status.Checks, status.Required)
+	}
+	if outcome := WaitOutcome(status, false); outcome != "" {
+		t.Errorf("WaitOutcome() = %q; expected none for a failed optional check", outcome)
+	}
+}
+
+// TestUpdatePullRequestBranchFromFork checks that the ancestry of a head just pushed to a fork,
+// and not yet known upstream, is checked in the fork
+func TestUpdatePullRequestBranchFromFork(t *testing.T) {
+	tests := []struct {
+		name            string
+		comparison      string
+		expectMutation  bool
+		expectedHeadSHA string
+	}{
+		{
+			name:            "Head up to date with base",
+			comparison:      "ahead",
+			expectedHeadSHA: "pushed",
+		},
+		{
+			name:            "Head behind base",
+			comparison:      "diverged",
+			expectMutation:  true,
+			expectedHeadSHA: "updated",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var expectedHeadOid string
+
+			upstreamMux := http.NewServeMux()
+			upstreamMux.HandleFunc("POST /graphql", func(w http.ResponseWriter, r *http.Request) {
+				var request struct {
+					Query     string `json:"query"`
+					Variables struct {
+						Input struct {
+							ExpectedHeadOid string `json:"expectedHeadOid"`
+						} `json:"input"`
+					} `json:"variables"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+					t.Errorf("decoding query: %v", err)
+				}
+				if strings.HasPrefix(request.Query, "mutation") {
+					expectedHeadOid = request.Variables.Input.ExpectedHeadOid
+					_, _ = w.Write([]byte(`{"data": {"updatePullRequestBranch": {"pullRequest": {"headRefOid": "updated"}}}}`))
+					return
+				}
+				_, _ = w.Write([]byte(`{"data": {"repository": {"pullRequest": {
+					"id": "PR_7",
+					"url": "https://github.com/owner/repo/pull/7",
+					"state": "OPEN",
+					"mergeable": "MERGEABLE",
+					"headRefName": "feature",
+					"baseRefName": "main",
+					"headRefOid": "stale",
+					"baseRef": {"target": {"oid": "base"}}
+				}}}}`))
+			})
+
+			forkMux := http.NewServeMux()
+			forkMux.HandleFunc("GET /repos/fork/repo/compare/{basehead}", func(w http.ResponseWriter, r *http.Request) {
+				if basehead := r.PathValue("basehead"); basehead != "base...pushed" {
+					t.Errorf("compared %q; expected %q", basehead, "base...pushed")
+				}
+				_, _ = w.Write([]byte(`{"status": "` + tt.comparison + `"}`))
+			})
+
+			client := newTestClient(t, upstreamMux)
+			fork := newTestClient(t, forkMux)
+			fork.repo = &Repo{Owner: "fork", Name: "repo"}
+
+			pullRequest := &PullRequest{Number: 7, HeadSHA: "pushed"}
+			if err := client.UpdatePullRequestBranch(pullRequest, "merge", fork); err != nil {
+				t.Fatalf("UpdatePullRequestBranch() error = %v", err)
+			}
+			if mutated := expectedHeadOid != ""; mutated != tt.expectMutation {
+				t.Errorf("UpdatePullRequestBranch() mutated = %v; expected %v", mutated, tt.expectMutation)
+			}
+			if tt.expectMutation && expectedHeadOid != "pushed" {
+				t.Errorf("UpdatePullRequestBranch() expectedHeadOid = %q; expected %q", expectedHeadOid, "pushed")
+			}
+			if pullRequest.HeadSHA != tt.expectedHeadSHA {
+				t.Errorf("UpdatePullRequestBranch() HeadSHA = %q; expected %q", pullRequest.HeadSHA, tt.expectedHeadSHA)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gofri/go-github-ratelimit/v2/github_ratelimit/github_primary_ratelimit"
+	"github.com/google/go-github/v89/github"
+)
+
+// ParseRepo parses an `owner/name` repository spec, with a bare `name` belonging to defaultOwner
+func ParseRepo(spec, defaultOwner string) (Repo, error) {
+	spec = strings.TrimSpace(spec)
+
+	owner, name, found := strings.Cut(spec, "/")
+	if !found {
+		owner, name = defaultOwner, spec
+	}
+
+	if owner == "" || name == "" || strings.Contains(name, "/") {
+		return Repo{}, fmt.Errorf("invalid repository %q: expected owner/name", spec)
+	}
+
+	return Repo{Owner: owner, Name: name}, nil
+}
+
+// SearchRepositories returns the repositories matching a GitHub search query, e.g. `org:x topic:go`
+func (c *Client) SearchRepositories(query string) (repos []Repo, err error) {
+	opts := &github.SearchOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		result, resp, err := c.V3.Search.Repositories(c.context, query, opts)
+		if err != nil {
+			return nil, fmt.Errorf("SearchRepositories(%q): %w", query, err)
+		}
+
+		if result.GetIncompleteResults() {
+			return nil, fmt.Errorf("SearchRepositories(%q): incomplete results", query)
+		}
+
+		for _, repository := range result.Repositories {
+			repos = append(repos, Repo{
+				Owner: repository.GetOwner().GetLogin(),
+				Name:  repository.GetName(),
+			})
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return repos, nil
+}
+
+// IsRateLimitError reports whether err results from exhausting the GitHub API rate limit
+func IsRateLimitError(err error) bool {
+	var reachedErr *github_primary_ratelimit.RateLimitReachedError
+	var rateLimitErr *github.RateLimitError
+	return errors.As(err, &reachedErr) || errors.As(err, &rateLimitErr)
+}
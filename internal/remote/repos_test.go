@@ -0,0 +1,71 @@
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/gofri/go-github-ratelimit/v2/github_ratelimit/github_primary_ratelimit"
+)
+
+func TestParseRepo(t *testing.T) {
+	tests := []struct {
+		name         string
+		spec         string
+		expectedRepo Repo
+		expectError  bool
+	}{
+		{
+			name:         "Owner and name",
+			spec:         "acme/widgets",
+			expectedRepo: Repo{Owner: "acme", Name: "widgets"},
+		},
+		{
+			name:         "Bare name uses default owner",
+			spec:         " widgets ",
+			expectedRepo: Repo{Owner: "default", Name: "widgets"},
+		},
+		{
+			name:        "Empty name",
+			spec:        "acme/",
+			expectError: true,
+		},
+		{
+			name:        "Too many components",
+			spec:        "acme/widgets/extra",
+			expectError: true,
+		},
+		{
+			name:        "Empty spec",
+			spec:        "",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, err := ParseRepo(tt.spec, "default")
+			if (err != nil) != tt.expectError {
+				t.Fatalf("ParseRepo(%q) error = %v; expectError %v", tt.spec, err, tt.expectError)
+			}
+			if repo != tt.expectedRepo {
+				t.Errorf("ParseRepo(%q) = %+v; expected %+v", tt.spec, repo, tt.expectedRepo)
+			}
+		})
+	}
+}
+
+func TestIsRateLimitError(t *testing.T) {
+	rateLimitErr := fmt.Errorf("GetRepositoryInfo(acme/widgets): %w", &github_primary_ratelimit.RateLimitReachedError{
+		Request: &http.Request{URL: &url.URL{Path: "/graphql"}},
+	})
+
+	if !IsRateLimitError(rateLimitErr) {
+		t.Errorf("IsRateLimitError(%v) = false; expected true", rateLimitErr)
+	}
+	if IsRateLimitError(errors.New("not found")) {
+		t.Error("IsRateLimitError(not found) = true; expected false")
+	}
+}
@@ -1,6 +1,7 @@
 package remote
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/shurcooL/githubv4"
@@ -29,3 +30,35 @@ func CommitMessage(message string) (commitMessage githubv4.CommitMessage) {
 		}
 	}
 }
+
+// NoreplyEmail returns the GitHub noreply email address for a user, which
+// GitHub uses to attribute commits and co-author trailers to the account
+func NoreplyEmail(id int64, login string) string {
+	return fmt.Sprintf("%d+%s@users.noreply.github.com", id, login)
+}
+
+// apiMergeMethod returns the GraphQL merge method for a merge method choice
+func apiMergeMethod(method string) (githubv4.PullRequestMergeMethod, error) {
+	switch method {
+	case AutoMergeMerge:
+		return githubv4.PullRequestMergeMethodMerge, nil
+	case AutoMergeSquash:
+		return githubv4.PullRequestMergeMethodSquash, nil
+	case AutoMergeRebase:
+		return githubv4.PullRequestMergeMethodRebase, nil
+	default:
+		return "", fmt.Errorf("unsupported merge method: %s", method)
+	}
+}
+
+// apiUpdateBranchMethod returns the GraphQL method of updating a pull request branch for a choice
+func apiUpdateBranchMethod(method string) (githubv4.PullRequestBranchUpdateMethod, error) {
+	switch method {
+	case AutoMergeMerge:
+		return githubv4.PullRequestBranchUpdateMethodMerge, nil
+	case AutoMergeRebase:
+		return githubv4.PullRequestBranchUpdateMethodRebase, nil
+	default:
+		return "", fmt.Errorf("unsupported update branch method: %s", method)
+	}
+}
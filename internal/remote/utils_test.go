@@ -277,6 +277,56 @@ func TestGetAutoMergeChoices(t *testing.T) {
 	}
 }
 
+func TestApiUpdateBranchMethod(t *testing.T) {
+	tests := []struct {
+		method      string
+		expected    githubv4.PullRequestBranchUpdateMethod
+		expectError bool
+	}{
+		{method: AutoMergeMerge, expected: githubv4.PullRequestBranchUpdateMethodMerge},
+		{method: AutoMergeRebase, expected: githubv4.PullRequestBranchUpdateMethodRebase},
+		{method: AutoMergeSquash, expectError: true},
+		{method: AutoMergeOff, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			result, err := apiUpdateBranchMethod(tt.method)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("apiUpdateBranchMethod(%s) error = %v; expectError %v", tt.method, err, tt.expectError)
+			}
+			if result != tt.expected {
+				t.Errorf("apiUpdateBranchMethod(%s) = %s; expected %s", tt.method, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAutoMergeChanges(t *testing.T) {
+	tests := []struct {
+		name            string
+		current         string
+		desired         string
+		expectedDisable bool
+		expectedEnable  bool
+	}{
+		{name: "Remains off", current: AutoMergeOff, desired: AutoMergeOff},
+		{name: "Unchanged method", current: AutoMergeSquash, desired: AutoMergeSquash},
+		{name: "Enabled", current: AutoMergeOff, desired: AutoMergeMerge, expectedEnable: true},
+		{name: "Disabled", current: AutoMergeRebase, desired: AutoMergeOff, expectedDisable: true},
+		{name: "Changed method", current: AutoMergeMerge, desired: AutoMergeSquash, expectedDisable: true, expectedEnable: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			disable, enable := autoMergeChanges(tt.current, tt.desired)
+			if disable != tt.expectedDisable || enable != tt.expectedEnable {
+				t.Errorf("autoMergeChanges(%s, %s) = %v, %v; expected %v, %v", tt.current, tt.desired, disable, enable, tt.expectedDisable, tt.expectedEnable)
+			}
+		})
+	}
+}
+
 func TestPullRequestUpdate(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -388,3 +438,81 @@ func TestPullRequestUpdate(t *testing.T) {
 		})
 	}
 }
+
+func TestNoreplyEmail(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       int64
+		login    string
+		expected string
+	}{
+		{
+			name:     "User",
+			id:       583231,
+			login:    "octocat",
+			expected: "583231+octocat@users.noreply.github.com",
+		},
+		{
+			name:     "Bot",
+			id:       49699333,
+			login:    "dependabot[bot]",
+			expected: "49699333+dependabot[bot]@users.noreply.github.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NoreplyEmail(tt.id, tt.login)
+			if result != tt.expected {
+				t.Errorf("NoreplyEmail(%d, %s) = %v; expected %v", tt.id, tt.login, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRepositoryInfoSelectMergeMethod(t *testing.T) {
+	tests := []struct {
+		name           string
+		repoInfo       repositoryInfo
+		preferred      string
+		expectedMethod string
+		expectError    bool
+	}{
+		{
+			name:           "Preferred method allowed",
+			repoInfo:       repositoryInfo{MergeCommitAllowed: true, SquashMergeAllowed: true},
+			preferred:      AutoMergeSquash,
+			expectedMethod: AutoMergeSquash,
+		},
+		{
+			name:        "Preferred method not allowed",
+			repoInfo:    repositoryInfo{SquashMergeAllowed: true, RebaseMergeAllowed: true},
+			preferred:   AutoMergeMerge,
+			expectError: true,
+		},
+		{
+			name:           "Auto selects first allowed",
+			repoInfo:       repositoryInfo{RebaseMergeAllowed: true},
+			preferred:      MergeAuto,
+			expectedMethod: AutoMergeRebase,
+		},
+		{
+			name:        "No methods allowed",
+			repoInfo:    repositoryInfo{},
+			preferred:   MergeAuto,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method, err := tt.repoInfo.SelectMergeMethod(tt.preferred)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("SelectMergeMethod(%s) error = %v; expectError %v", tt.preferred, err, tt.expectError)
+			}
+			if method != tt.expectedMethod {
+				t.Errorf("SelectMergeMethod(%s) = %q; expected %q", tt.preferred, method, tt.expectedMethod)
+			}
+		})
+	}
+}
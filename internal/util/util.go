@@ -11,7 +11,9 @@ import (
 	"os"
 	"os/exec"
 	"regexp"
+	"runtime"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -31,6 +33,14 @@ func IsBinaryInPath(name string) bool {
 	return err == nil
 }
 
+// ShellCommand returns a command that runs commandLine via the platform shell
+func ShellCommand(ctx context.Context, commandLine string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", commandLine)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", commandLine)
+}
+
 // GetCliAuthToken tries to get a GitHub token by execing `gh auth token`
 func GetCliAuthToken() string {
 	if !viper.GetBool("no-cli-token") && IsBinaryInPath("gh") {
@@ -69,6 +79,48 @@ func GithubActionsContext() *RepositoryContext {
 	return nil
 }
 
+// GithubActionsActor returns the login of the user that triggered a GitHub Actions run,
+// preferring the event payload sender, or an empty string outside GitHub Actions
+func GithubActionsActor() string {
+	if eventPath := os.Getenv("GITHUB_EVENT_PATH"); eventPath != "" {
+		if payload, err := os.ReadFile(eventPath); err == nil {
+			var event struct {
+				Sender struct {
+					Login string `json:"login"`
+				} `json:"sender"`
+			}
+			if json.Unmarshal(payload, &event) == nil && event.Sender.Login != "" {
+				return event.Sender.Login
+			}
+		}
+	}
+
+	return cmp.Or(
+		os.Getenv("GITHUB_TRIGGERING_ACTOR"), // re-run context
+		os.Getenv("GITHUB_ACTOR"),
+	)
+}
+
+// CIRunURL returns the URL of the current CI run, if known: a GitHub Actions workflow run,
+// or the build URL of GitLab CI, Jenkins, CircleCI or Buildkite
+func CIRunURL() string {
+	if runId := os.Getenv("GITHUB_RUN_ID"); runId != "" && os.Getenv("GITHUB_REPOSITORY") != "" {
+		runURL := fmt.Sprintf("%s/%s/actions/runs/%s",
+			cmp.Or(os.Getenv("GITHUB_SERVER_URL"), "https://github.com"), os.Getenv("GITHUB_REPOSITORY"), runId)
+		if attempt := os.Getenv("GITHUB_RUN_ATTEMPT"); attempt != "" && attempt != "1" {
+			runURL = fmt.Sprintf("%s/attempts/%s", runURL, attempt)
+		}
+		return runURL
+	}
+
+	return cmp.Or(
+		os.Getenv("CI_JOB_URL"),          // GitLab CI
+		os.Getenv("BUILD_URL"),           // Jenkins
+		os.Getenv("CIRCLE_BUILD_URL"),    // CircleCI
+		os.Getenv("BUILDKITE_BUILD_URL"), // Buildkite
+	)
+}
+
 // IsCommitHash returns true if the ref looks like a commit hash
 func IsCommitHash(ref string) bool {
 	commitHashPattern := `^[0-9a-f]{7,40}$`
@@ -147,6 +199,135 @@ func QualifiedRefName(refName string, refType string) (string, error) {
 	return strings.Join([]string{"refs", refType, refName}, "/"), nil
 }
 
+// MatchGlob reports whether a slash-separated path matches a glob pattern.
+// In addition to path.Match syntax, `**` matches any number of path components,
+// and patterns without a slash are matched against the base name only.
+func MatchGlob(pattern, name string) bool {
+	if !strings.Contains(pattern, "/") {
+		if i := strings.LastIndex(name, "/"); i >= 0 {
+			name = name[i+1:]
+		}
+	}
+
+	re, err := globRegexp(pattern)
+	if err != nil {
+		return false
+	}
+
+	return re.MatchString(name)
+}
+
+// MatchBranch reports whether a branch name matches any of the glob patterns, or there are none.
+// Unlike MatchGlob, patterns always match the full name, so `*` does not match a slash, but `**` does.
+func MatchBranch(patterns []string, branch string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+
+	for _, pattern := range patterns {
+		if re, err := globRegexp(pattern); err == nil && re.MatchString(branch) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsGlob reports whether a spec is a glob pattern rather than a plain name
+func IsGlob(spec string) bool {
+	return strings.ContainsAny(spec, "*?[")
+}
+
+// ExpandBranches expands branch specs into branch names, preserving order. Globs are matched
+// against the branches returned by listBranches with MatchBranch, and must match at least one;
+// names are returned as is, so may be new branches.
+func ExpandBranches(specs []string, listBranches func() ([]string, error)) ([]string, error) {
+	var branchNames []string
+	var err error
+
+	branches := make([]string, 0, len(specs))
+	errs := make([]error, 0)
+
+	for _, spec := range specs {
+		if !IsGlob(spec) {
+			branches = append(branches, spec)
+			continue
+		}
+
+		if branchNames == nil {
+			if branchNames, err = listBranches(); err != nil {
+				return nil, err
+			}
+		}
+
+		matched := false
+		for _, name := range branchNames {
+			if MatchBranch([]string{spec}, name) {
+				branches = append(branches, name)
+				matched = true
+			}
+		}
+		if !matched {
+			errs = append(errs, fmt.Errorf("branch glob %q matches no branches", spec))
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return slices.Compact(branches), nil
+}
+
+// ValidateGlob checks that a glob pattern is well-formed, as MatchGlob matches nothing otherwise
+func ValidateGlob(pattern string) error {
+	_, err := globRegexp(pattern)
+	return err
+}
+
+// globRegexp translates a glob pattern into an anchored regular expression
+func globRegexp(pattern string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString("^")
+
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					// `**/` matches zero or more leading directories
+					i++
+					sb.WriteString("(?:.*/)?")
+				} else {
+					sb.WriteString(".*")
+				}
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(pattern[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated character class in %q", pattern)
+			}
+			class := pattern[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			i += end
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	sb.WriteString("$")
+
+	return regexp.Compile(sb.String())
+}
+
 // BuildCommitMessage generates a commit message from the message and trailers configuration
 func BuildCommitMessage() (message string) {
 	messageParts := []string{}
@@ -164,18 +345,25 @@ func BuildCommitMessage() (message string) {
 	return
 }
 
-// BuildTrailers generates the complete list of trailers from the configuration
+// BuildTrailers generates the complete list of trailers from the configuration.
+// Explicit co-authors take precedence over the single user-name/user-email trailer.
 func BuildTrailers() (trailers []string) {
 	if trailerKey := viper.GetString("user-trailer"); trailerKey != "" && trailerKey != "-" {
-		var userParts []string
-		if userName := viper.GetString("user-name"); userName != "" {
-			userParts = append(userParts, userName)
-		}
-		if userEmail := viper.GetString("user-email"); userEmail != "" {
-			userParts = append(userParts, fmt.Sprintf("<%s>", userEmail))
-		}
-		if len(userParts) > 0 {
-			trailers = append(trailers, fmt.Sprintf("%s: %s", trailerKey, strings.Join(userParts, " ")))
+		if coAuthors := viper.GetStringSlice("co-author"); len(coAuthors) > 0 {
+			for _, coAuthor := range coAuthors {
+				trailers = append(trailers, fmt.Sprintf("%s: %s", trailerKey, coAuthor))
+			}
+		} else {
+			var userParts []string
+			if userName := viper.GetString("user-name"); userName != "" {
+				userParts = append(userParts, userName)
+			}
+			if userEmail := viper.GetString("user-email"); userEmail != "" {
+				userParts = append(userParts, fmt.Sprintf("<%s>", userEmail))
+			}
+			if len(userParts) > 0 {
+				trailers = append(trailers, fmt.Sprintf("%s: %s", trailerKey, strings.Join(userParts, " ")))
+			}
 		}
 	}
 	trailerMap := viper.GetStringMapString("trailer")
@@ -185,6 +373,72 @@ func BuildTrailers() (trailers []string) {
 	return
 }
 
+// HasTrailer reports whether the final paragraph of a commit message contains the `key: value` trailer.
+// Keys are matched case-insensitively, as git does.
+func HasTrailer(message, key, value string) bool {
+	paragraphs := strings.Split(strings.TrimSpace(strings.ReplaceAll(message, "\r\n", "\n")), "\n\n")
+	for line := range strings.SplitSeq(paragraphs[len(paragraphs)-1], "\n") {
+		k, v, found := strings.Cut(line, ":")
+		if found && strings.EqualFold(strings.TrimSpace(k), key) && strings.TrimSpace(v) == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseIdentity parses a git identity of the form `Name <email>`
+func ParseIdentity(identity string) (name, email string, err error) {
+	identity = strings.TrimSpace(identity)
+	open := strings.LastIndex(identity, "<")
+	if open < 0 || !strings.HasSuffix(identity, ">") {
+		return "", "", fmt.Errorf("invalid identity %q: expected \"Name <email>\"", identity)
+	}
+
+	name = strings.TrimSpace(identity[:open])
+	email = strings.TrimSpace(identity[open+1 : len(identity)-1])
+
+	if name == "" {
+		return "", "", fmt.Errorf("invalid identity %q: empty name", identity)
+	}
+	if email == "" || strings.ContainsAny(email, "<> ") {
+		return "", "", fmt.Errorf("invalid identity %q: invalid email", identity)
+	}
+
+	return name, email, nil
+}
+
+// dateLayouts lists the date formats accepted by ParseDate, in order of preference
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05",
+	time.RFC1123Z,
+	"Mon Jan 2 15:04:05 2006 -0700",
+	"2006-01-02",
+}
+
+// ParseDate parses a commit date in RFC 3339, git-style or `@<unix-timestamp>` format
+func ParseDate(date string) (time.Time, error) {
+	date = strings.TrimSpace(date)
+
+	if seconds, ok := strings.CutPrefix(date, "@"); ok {
+		unix, err := strconv.ParseInt(seconds, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid unix timestamp %q: %w", date, err)
+		}
+		return time.Unix(unix, 0).UTC(), nil
+	}
+
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, date); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid date %q", date)
+}
+
 func ShortJson(r any) string {
 	bytes, err := json.Marshal(r)
 	if err != nil {
@@ -215,6 +469,20 @@ func SliceChain[T any](slices ...[]T) iter.Seq[T] {
 	}
 }
 
+// Unique returns the distinct elements of s, in order of first occurrence
+func Unique[T comparable](s []T) []T {
+	seen := make(map[T]struct{}, len(s))
+	unique := make([]T, 0, len(s))
+	for _, e := range s {
+		if _, ok := seen[e]; ok {
+			continue
+		}
+		seen[e] = struct{}{}
+		unique = append(unique, e)
+	}
+	return unique
+}
+
 func MapValues[T any](m map[string]T) []T {
 	slice := make([]T, 0, len(m))
 	for _, v := range m {
@@ -2,8 +2,10 @@ package util
 
 import (
 	"os"
+	"path/filepath"
 	"slices"
 	"testing"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -147,6 +149,63 @@ func TestGithubActionsContext(t *testing.T) {
 	}
 }
 
+func TestCIRunURL(t *testing.T) {
+	tests := []struct {
+		name        string
+		envVars     map[string]string
+		expectedURL string
+	}{
+		{
+			name: "GitHub Actions",
+			envVars: map[string]string{
+				"GITHUB_SERVER_URL": "https://github.com",
+				"GITHUB_REPOSITORY": "owner/repo",
+				"GITHUB_RUN_ID":     "123",
+			},
+			expectedURL: "https://github.com/owner/repo/actions/runs/123",
+		},
+		{
+			name: "GitHub Actions re-run",
+			envVars: map[string]string{
+				"GITHUB_SERVER_URL":  "https://ghe.example.com",
+				"GITHUB_REPOSITORY":  "owner/repo",
+				"GITHUB_RUN_ID":      "123",
+				"GITHUB_RUN_ATTEMPT": "2",
+			},
+			expectedURL: "https://ghe.example.com/owner/repo/actions/runs/123/attempts/2",
+		},
+		{
+			name: "Jenkins",
+			envVars: map[string]string{
+				"BUILD_URL": "https://jenkins.example.com/job/x/1/",
+			},
+			expectedURL: "https://jenkins.example.com/job/x/1/",
+		},
+		{
+			name:        "Unknown",
+			envVars:     map[string]string{},
+			expectedURL: "",
+		},
+	}
+
+	ciVars := []string{
+		"GITHUB_SERVER_URL", "GITHUB_REPOSITORY", "GITHUB_RUN_ID", "GITHUB_RUN_ATTEMPT",
+		"CI_JOB_URL", "BUILD_URL", "CIRCLE_BUILD_URL", "BUILDKITE_BUILD_URL",
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range ciVars {
+				t.Setenv(key, tt.envVars[key])
+			}
+
+			if result := CIRunURL(); result != tt.expectedURL {
+				t.Errorf("CIRunURL() = %q; expected %q", result, tt.expectedURL)
+			}
+		})
+	}
+}
+
 func TestIsCommitHash(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -434,6 +493,22 @@ func TestBuildTrailers(t *testing.T) {
 				"Reviewed-By: Jane Smith",
 			},
 		},
+		{
+			name: "Co-authors override user trailer",
+			viperSettings: map[string]any{
+				"user-trailer": "Co-Authored-By",
+				"user-name":    "jenkins",
+				"user-email":   "jenkins@example.com",
+				"co-author": []string{
+					"John Doe <1234+jdoe@users.noreply.github.com>",
+					"Jane Smith <5678+jsmith@users.noreply.github.com>",
+				},
+			},
+			expectedOutput: []string{
+				"Co-Authored-By: John Doe <1234+jdoe@users.noreply.github.com>",
+				"Co-Authored-By: Jane Smith <5678+jsmith@users.noreply.github.com>",
+			},
+		},
 		{
 			name: "Only additional trailers",
 			viperSettings: map[string]any{
@@ -464,3 +539,407 @@ func TestBuildTrailers(t *testing.T) {
 		})
 	}
 }
+
+func TestHasTrailer(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		expected bool
+	}{
+		{
+			name:     "Trailer in final paragraph",
+			message:  "Update files\n\nCo-Authored-By: John Doe <john.doe@example.com>\nCommitted-Via: ghup\n",
+			expected: true,
+		},
+		{
+			name:     "Case-insensitive key",
+			message:  "Update files\r\n\r\ncommitted-via: ghup",
+			expected: true,
+		},
+		{
+			name:     "Trailer only in body",
+			message:  "Update files\n\nCommitted-Via: ghup\n\nSigned-off-by: John Doe <john.doe@example.com>",
+			expected: false,
+		},
+		{
+			name:     "Different value",
+			message:  "Update files\n\nCommitted-Via: web",
+			expected: false,
+		},
+		{
+			name:     "No trailers",
+			message:  "Update files",
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := HasTrailer(tt.message, "Committed-Via", "ghup"); result != tt.expected {
+				t.Errorf("HasTrailer(%q) = %v; expected %v", tt.message, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseIdentity(t *testing.T) {
+	tests := []struct {
+		name          string
+		identity      string
+		expectedName  string
+		expectedEmail string
+		expectError   bool
+	}{
+		{
+			name:          "Valid identity",
+			identity:      "John Doe <john.doe@example.com>",
+			expectedName:  "John Doe",
+			expectedEmail: "john.doe@example.com",
+		},
+		{
+			name:          "Surrounding whitespace",
+			identity:      "  John Doe   < john.doe@example.com > ",
+			expectedName:  "John Doe",
+			expectedEmail: "john.doe@example.com",
+		},
+		{
+			name:        "Missing email",
+			identity:    "John Doe",
+			expectError: true,
+		},
+		{
+			name:        "Missing name",
+			identity:    "<john.doe@example.com>",
+			expectError: true,
+		},
+		{
+			name:        "Empty email",
+			identity:    "John Doe <>",
+			expectError: true,
+		},
+		{
+			name:        "Unterminated email",
+			identity:    "John Doe <john.doe@example.com",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, email, err := ParseIdentity(tt.identity)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("ParseIdentity(%q) expected error, got nil", tt.identity)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseIdentity(%q) unexpected error: %v", tt.identity, err)
+			}
+			if name != tt.expectedName || email != tt.expectedEmail {
+				t.Errorf("ParseIdentity(%q) = (%q, %q); expected (%q, %q)", tt.identity, name, email, tt.expectedName, tt.expectedEmail)
+			}
+		})
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		name        string
+		date        string
+		expected    time.Time
+		expectError bool
+	}{
+		{
+			name:     "RFC 3339",
+			date:     "2024-03-01T12:34:56Z",
+			expected: time.Date(2024, 3, 1, 12, 34, 56, 0, time.UTC),
+		},
+		{
+			name:     "Git format with offset",
+			date:     "2024-03-01 14:34:56 +0200",
+			expected: time.Date(2024, 3, 1, 12, 34, 56, 0, time.UTC),
+		},
+		{
+			name:     "Unix timestamp",
+			date:     "@1709296496",
+			expected: time.Date(2024, 3, 1, 12, 34, 56, 0, time.UTC),
+		},
+		{
+			name:     "Date only",
+			date:     "2024-03-01",
+			expected: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:        "Invalid timestamp",
+			date:        "@yesterday",
+			expectError: true,
+		},
+		{
+			name:        "Invalid date",
+			date:        "last tuesday",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseDate(tt.date)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("ParseDate(%q) expected error, got nil", tt.date)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseDate(%q) unexpected error: %v", tt.date, err)
+			}
+			if !result.Equal(tt.expected) {
+				t.Errorf("ParseDate(%q) = %v; expected %v", tt.date, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGithubActionsActor(t *testing.T) {
+	eventPath := filepath.Join(t.TempDir(), "event.json")
+	if err := os.WriteFile(eventPath, []byte(`{"sender":{"login":"octocat"}}`), 0o600); err != nil {
+		t.Fatalf("writing event payload: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		envVars       map[string]string
+		expectedActor string
+	}{
+		{
+			name: "Event payload sender",
+			envVars: map[string]string{
+				"GITHUB_EVENT_PATH": eventPath,
+				"GITHUB_ACTOR":      "someone-else",
+			},
+			expectedActor: "octocat",
+		},
+		{
+			name: "Triggering actor",
+			envVars: map[string]string{
+				"GITHUB_TRIGGERING_ACTOR": "rerunner",
+				"GITHUB_ACTOR":            "original",
+			},
+			expectedActor: "rerunner",
+		},
+		{
+			name: "Actor only",
+			envVars: map[string]string{
+				"GITHUB_ACTOR": "original",
+			},
+			expectedActor: "original",
+		},
+		{
+			name:          "Not in GitHub Actions",
+			envVars:       map[string]string{},
+			expectedActor: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"GITHUB_EVENT_PATH", "GITHUB_TRIGGERING_ACTOR", "GITHUB_ACTOR"} {
+				t.Setenv(key, tt.envVars[key])
+			}
+
+			result := GithubActionsActor()
+			if result != tt.expectedActor {
+				t.Errorf("GithubActionsActor() = %v; expected %v", result, tt.expectedActor)
+			}
+		})
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "cmd/root.go", true},
+		{"*.go", "main.go.orig", false},
+		{"cmd/*.go", "cmd/root.go", true},
+		{"cmd/*.go", "cmd/sub/root.go", false},
+		{"cmd/**/*.go", "cmd/root.go", true},
+		{"cmd/**/*.go", "cmd/sub/deep/root.go", true},
+		{"**/*.json", "package.json", true},
+		{"**/*.json", "a/b/c.json", true},
+		{"docs/**", "docs/cmd/ghup.md", true},
+		{"file?.txt", "file1.txt", true},
+		{"file?.txt", "file10.txt", false},
+		{"file[0-9].txt", "file5.txt", true},
+		{"file[!0-9].txt", "file5.txt", false},
+		{"release/*", "release/1.0", true},
+		{"release/*", "release/1.0/hotfix", false},
+		{"[unterminated", "[unterminated", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+"|"+tt.name, func(t *testing.T) {
+			result := MatchGlob(tt.pattern, tt.name)
+			if result != tt.expected {
+				t.Errorf("MatchGlob(%q, %q) = %v; expected %v", tt.pattern, tt.name, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMatchBranch(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		branch   string
+		expected bool
+	}{
+		{"No patterns", nil, "main", true},
+		{"No patterns with slash", nil, "renovate/go-1.x", true},
+		{"Star", []string{"*"}, "main", true},
+		{"Star with slash", []string{"*"}, "renovate/go-1.x", false},
+		{"Double star with slash", []string{"**"}, "renovate/go-1.x", true},
+		{"Prefix", []string{"renovate/*"}, "renovate/go-1.x", true},
+		{"Prefix with nested slash", []string{"renovate/*"}, "renovate/go/1.x", false},
+		{"Prefix with double star", []string{"renovate/**"}, "renovate/go/1.x", true},
+		{"Base name only", []string{"go-*"}, "renovate/go-1.x", false},
+		{"Any pattern", []string{"main", "release/*"}, "release/1.0", true},
+		{"No matching pattern", []string{"main", "release/*"}, "feature/x", false},
+		{"Malformed pattern", []string{"[unterminated"}, "[unterminated", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := MatchBranch(tt.patterns, tt.branch)
+			if result != tt.expected {
+				t.Errorf("MatchBranch(%q, %q) = %v; expected %v", tt.patterns, tt.branch, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateGlob(t *testing.T) {
+	tests := []struct {
+		pattern     string
+		expectError bool
+	}{
+		{pattern: "*.go"},
+		{pattern: "cmd/**/*.go"},
+		{pattern: "file[0-9].txt"},
+		{pattern: "[unterminated", expectError: true},
+		{pattern: "config/[a-z.yaml", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			if err := ValidateGlob(tt.pattern); (err != nil) != tt.expectError {
+				t.Errorf("ValidateGlob(%q) error = %v; expectError %v", tt.pattern, err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestUnique(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []string
+		expected []string
+	}{
+		{
+			name:     "Empty",
+			input:    nil,
+			expected: []string{},
+		},
+		{
+			name:     "Adjacent duplicates",
+			input:    []string{"a", "a", "b"},
+			expected: []string{"a", "b"},
+		},
+		{
+			name:     "Non-adjacent duplicates keep first occurrence",
+			input:    []string{"Jane <jane@example.com>", "Hubot <1+hubot@users.noreply.github.com>", "Jane <jane@example.com>"},
+			expected: []string{"Jane <jane@example.com>", "Hubot <1+hubot@users.noreply.github.com>"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := Unique(tt.input); !slices.Equal(result, tt.expected) {
+				t.Errorf("Unique(%v) = %v; expected %v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExpandBranches(t *testing.T) {
+	branchNames := []string{"main", "release/v1", "release/v1/hotfix", "renovate/go/1.x"}
+
+	tests := []struct {
+		name        string
+		specs       []string
+		expected    []string
+		expectList  bool
+		expectError bool
+	}{
+		{
+			name:     "Names only",
+			specs:    []string{"main", "bot/new"},
+			expected: []string{"main", "bot/new"},
+		},
+		{
+			name:       "Glob excludes nested branches",
+			specs:      []string{"release/*"},
+			expected:   []string{"release/v1"},
+			expectList: true,
+		},
+		{
+			name:       "Double star includes nested branches",
+			specs:      []string{"release/**"},
+			expected:   []string{"release/v1", "release/v1/hotfix"},
+			expectList: true,
+		},
+		{
+			name:       "Glob matches nested branch",
+			specs:      []string{"renovate/*/*"},
+			expected:   []string{"renovate/go/1.x"},
+			expectList: true,
+		},
+		{
+			name:       "Names and globs preserve order",
+			specs:      []string{"bot/new", "release/*", "main"},
+			expected:   []string{"bot/new", "release/v1", "main"},
+			expectList: true,
+		},
+		{
+			name:        "Glob matching no branches",
+			specs:       []string{"feature/*"},
+			expectList:  true,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listed := false
+			listBranches := func() ([]string, error) {
+				listed = true
+				return branchNames, nil
+			}
+
+			result, err := ExpandBranches(tt.specs, listBranches)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("ExpandBranches(%q) error = %v; expectError %v", tt.specs, err, tt.expectError)
+			}
+			if !slices.Equal(result, tt.expected) {
+				t.Errorf("ExpandBranches(%q) = %q; expected %q", tt.specs, result, tt.expected)
+			}
+			if listed != tt.expectList {
+				t.Errorf("ExpandBranches(%q) listed branches = %v; expected %v", tt.specs, listed, tt.expectList)
+			}
+		})
+	}
+}
@@ -5,7 +5,7 @@ import (
 	"fmt"
 	"os"
 
-	"github.com/nexthink-oss/ghup/cmd"
+	ghup "github.com/nexthink-oss/ghup/cmd"
 )
 
 var (
@@ -15,9 +15,10 @@ var (
 )
 
 func main() {
-	cmd := cmd.New()
+	cmd := ghup.New()
 	cmd.Version = fmt.Sprintf("%s-%s (built %s)", version, commit, date)
 	if err := cmd.ExecuteContext(context.Background()); err != nil {
-		os.Exit(1)
+		// outcomes such as a pull request closing without merge have distinct exit codes
+		os.Exit(ghup.ExitCode(err))
 	}
 }